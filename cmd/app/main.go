@@ -8,13 +8,10 @@ import (
 	"syscall"
 
 	"tokenlaunch/internal/api"
-	"tokenlaunch/internal/classifier"
+	"tokenlaunch/internal/app"
 	"tokenlaunch/internal/config"
-	"tokenlaunch/internal/notifier"
-	"tokenlaunch/internal/queue"
 	"tokenlaunch/internal/redis"
 	"tokenlaunch/internal/storage"
-	"tokenlaunch/internal/worker"
 )
 
 func main() {
@@ -35,27 +32,32 @@ func main() {
 	}
 	defer rdb.Close()
 
-	consumer, err := queue.NewKafkaConsumer(cfg.Queue.Brokers, cfg.Queue.GroupID, cfg.Queue.Topic)
+	consumer, err := app.OpenConsumer(cfg.Queue, cfg.Redis.Addr)
 	if err != nil {
 		log.Fatalf("failed to create consumer: %v", err)
 	}
 	defer consumer.Close()
 
-	cl := classifier.NewOpenRouter(cfg.Classifier.APIKey, cfg.Classifier.Model)
-	nt := notifier.NewTelegram(cfg.Notifier.TelegramToken, cfg.Notifier.TelegramChatIDs)
+	publisher, err := app.OpenPublisher(cfg.Queue, cfg.Redis.Addr)
+	if err != nil {
+		log.Fatalf("failed to create queue: %v", err)
+	}
+	defer publisher.Close()
 
-	server := api.NewServer(repo, rdb)
+	deadLetters, err := app.OpenDeadLetters(cfg.Queue)
+	if err != nil {
+		log.Fatalf("failed to create dead-letter reader: %v", err)
+	}
+	if deadLetters != nil {
+		defer deadLetters.Close()
+	}
 
-	w := worker.NewConsumer(consumer, repo, cl, nt, server)
+	server := api.NewServer(repo, rdb, publisher, cfg.Server.IngestTokens, cfg.Scraper.DedupTTL, deadLetters)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	go func() {
-		if err := w.Start(ctx); err != nil {
-			log.Printf("consumer error: %v", err)
-		}
-	}()
+	app.StartConsumer(ctx, cfg, consumer, repo, server)
 
 	go func() {
 		log.Printf("server starting on %s", cfg.Server.Port)
@@ -7,12 +7,9 @@ import (
 	"os/signal"
 	"syscall"
 
-	"tokenlaunch/internal/classifier"
+	"tokenlaunch/internal/app"
 	"tokenlaunch/internal/config"
-	"tokenlaunch/internal/notifier"
-	"tokenlaunch/internal/queue"
 	"tokenlaunch/internal/storage"
-	"tokenlaunch/internal/worker"
 )
 
 func main() {
@@ -27,25 +24,16 @@ func main() {
 	}
 	defer repo.Close()
 
-	consumer, err := queue.NewKafkaConsumer(cfg.Queue.Brokers, cfg.Queue.GroupID, cfg.Queue.Topic)
+	consumer, err := app.OpenConsumer(cfg.Queue, cfg.Redis.Addr)
 	if err != nil {
 		log.Fatalf("failed to create consumer: %v", err)
 	}
 	defer consumer.Close()
 
-	cl := classifier.NewOpenRouter(cfg.Classifier.APIKey, cfg.Classifier.Model)
-	nt := notifier.NewTelegram(cfg.Notifier.TelegramToken, cfg.Notifier.TelegramChatIDs)
-
-	w := worker.NewConsumer(consumer, repo, cl, nt)
-
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	go func() {
-		if err := w.Start(ctx); err != nil {
-			log.Printf("consumer error: %v", err)
-		}
-	}()
+	app.StartConsumer(ctx, cfg, consumer, repo, nopBroadcaster{})
 
 	log.Printf("consumer started")
 
@@ -56,3 +44,8 @@ func main() {
 	log.Printf("shutting down")
 	cancel()
 }
+
+// nopBroadcaster drops dashboard updates; the standalone consumer has no SSE clients.
+type nopBroadcaster struct{}
+
+func (nopBroadcaster) Broadcast(string) {}
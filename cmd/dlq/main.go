@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"tokenlaunch/internal/app"
+	"tokenlaunch/internal/config"
+	"tokenlaunch/internal/queue"
+)
+
+const usage = `usage:
+  dlq list [-limit N]
+  dlq replay -partition P -offset O
+  dlq replay -all [-limit N]`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	if cfg.Queue.Backend != "kafka" {
+		log.Fatalf("dlq only supports the kafka backend, read %s with XRANGE instead", cfg.Queue.DLQTopic)
+	}
+
+	dl, err := queue.NewDeadLetters(cfg.Queue.Brokers, cfg.Queue.DLQTopic, app.KafkaOptions(cfg.Queue))
+	if err != nil {
+		log.Fatalf("failed to connect to kafka: %v", err)
+	}
+	defer dl.Close()
+
+	ctx := context.Background()
+
+	switch os.Args[1] {
+	case "list":
+		fs := flag.NewFlagSet("list", flag.ExitOnError)
+		limit := fs.Int("limit", 50, "most recent entries per partition")
+		fs.Parse(os.Args[2:])
+
+		letters, err := dl.List(ctx, *limit)
+		if err != nil {
+			log.Fatalf("failed to list dead letters: %v", err)
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "PARTITION\tOFFSET\tFAILED AT\tATTEMPTS\tKEY\tERROR")
+		for _, l := range letters {
+			fmt.Fprintf(tw, "%d\t%d\t%s\t%d\t%s\t%s\n",
+				l.Partition, l.Offset, l.FailedAt.Format(time.RFC3339), l.Attempts, l.Key, l.Error)
+		}
+		tw.Flush()
+
+	case "replay":
+		fs := flag.NewFlagSet("replay", flag.ExitOnError)
+		partition := fs.Int("partition", -1, "partition of the entry to replay")
+		offset := fs.Int64("offset", -1, "offset of the entry to replay")
+		all := fs.Bool("all", false, "replay every listed entry")
+		limit := fs.Int("limit", 50, "most recent entries per partition, with -all")
+		fs.Parse(os.Args[2:])
+
+		if *all {
+			letters, err := dl.List(ctx, *limit)
+			if err != nil {
+				log.Fatalf("failed to list dead letters: %v", err)
+			}
+			for _, l := range letters {
+				if err := dl.Replay(ctx, l.Partition, l.Offset); err != nil {
+					log.Fatalf("failed to replay %d/%d: %v", l.Partition, l.Offset, err)
+				}
+				log.Printf("replayed %d/%d", l.Partition, l.Offset)
+			}
+			return
+		}
+
+		if *partition < 0 || *offset < 0 {
+			fmt.Fprintln(os.Stderr, usage)
+			os.Exit(2)
+		}
+		if err := dl.Replay(ctx, int32(*partition), *offset); err != nil {
+			log.Fatalf("failed to replay %d/%d: %v", *partition, *offset, err)
+		}
+		log.Printf("replayed %d/%d", *partition, *offset)
+
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"tokenlaunch/internal/app"
+	"tokenlaunch/internal/classifier"
+	"tokenlaunch/internal/config"
+	"tokenlaunch/internal/notifier"
+	"tokenlaunch/internal/queue"
+	"tokenlaunch/internal/storage"
+	"tokenlaunch/internal/worker"
+)
+
+// replay re-runs messages published in a time range through the consumer
+// pipeline, e.g. to backfill classifications after a classifier change.
+func main() {
+	from := flag.String("from", "", "start of the range, RFC 3339 or a duration ago (e.g. 24h)")
+	to := flag.String("to", "", "end of the range, RFC 3339 or a duration ago (default: now)")
+	notify := flag.Bool("notify", false, "queue Telegram alerts for replayed messages")
+	flag.Parse()
+
+	start, err := parseTime(*from)
+	if err != nil || *from == "" {
+		log.Fatalf("invalid -from %q", *from)
+	}
+	end, err := parseTime(*to)
+	if err != nil {
+		log.Fatalf("invalid -to %q", *to)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+	if cfg.Queue.Backend != "kafka" {
+		log.Fatalf("replay only supports the kafka backend")
+	}
+
+	repo, err := storage.NewPostgres(cfg.Storage.DSN)
+	if err != nil {
+		log.Fatalf("failed to connect to storage: %v", err)
+	}
+	defer repo.Close()
+
+	replay, err := queue.NewKafkaReplay(cfg.Queue.Brokers, cfg.Queue.Topic, app.KafkaOptions(cfg.Queue), start, end)
+	if err != nil {
+		log.Fatalf("failed to create replay: %v", err)
+	}
+	defer replay.Close()
+
+	cl := classifier.NewOpenRouter(cfg.Classifier.APIKey, cfg.Classifier.Model)
+
+	// Alerts go to the outbox and are sent by the running consumer's dispatcher
+	var channels []string
+	if *notify {
+		for name := range notifier.TelegramChannels(cfg.Notifier.TelegramToken, cfg.Notifier.TelegramChatIDs) {
+			channels = append(channels, name)
+		}
+	}
+
+	w := worker.NewConsumer(replay, repo, cl, channels, nopBroadcaster{})
+	w.Reprocess()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	log.Printf("replaying %s to %s", start.Format(time.RFC3339), end.Format(time.RFC3339))
+	if err := w.Start(ctx); err != nil {
+		log.Printf("replay error: %v", err)
+		os.Exit(1)
+	}
+	log.Printf("replay finished")
+}
+
+// parseTime reads an RFC 3339 time or a duration before now. Empty is now.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Now(), nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// nopBroadcaster drops dashboard updates; replays have no SSE clients.
+type nopBroadcaster struct{}
+
+func (nopBroadcaster) Broadcast(string) {}
@@ -5,12 +5,13 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 
+	"tokenlaunch/internal/app"
 	"tokenlaunch/internal/config"
 	"tokenlaunch/internal/queue"
 	"tokenlaunch/internal/redis"
-	"tokenlaunch/internal/scraper"
 	"tokenlaunch/internal/worker"
 )
 
@@ -26,20 +27,27 @@ func main() {
 	}
 	defer rdb.Close()
 
-	publisher, err := queue.NewKafka(cfg.Queue.Brokers, cfg.Queue.Topic)
+	publisher, err := app.OpenAsyncPublisher(cfg.Queue, cfg.Redis.Addr)
 	if err != nil {
 		log.Fatalf("failed to create queue: %v", err)
 	}
-	defer publisher.Close()
-
-	nitter := scraper.NewNitter(cfg.Scraper.Instance)
-
-	w := worker.NewScraper(nitter, publisher, rdb, cfg.Scraper.Instance, cfg.Scraper.Interval)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	go w.Start(ctx)
+	var wg sync.WaitGroup
+	workers := app.StartScrapers(ctx, &wg, cfg, publisher, rdb)
+
+	// Outcomes are still reported while the publisher flushes on shutdown
+	watched := make(chan struct{})
+	if r, ok := publisher.(queue.DeliveryReporter); ok {
+		go func() {
+			defer close(watched)
+			worker.WatchDeliveries(context.Background(), r, workers)
+		}()
+	} else {
+		close(watched)
+	}
 
 	log.Printf("scraper started")
 
@@ -49,4 +57,10 @@ func main() {
 
 	log.Printf("shutting down")
 	cancel()
+	wg.Wait()
+
+	if err := publisher.Close(); err != nil {
+		log.Printf("failed to close queue: %v", err)
+	}
+	<-watched
 }
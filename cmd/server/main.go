@@ -7,7 +7,9 @@ import (
 	"syscall"
 
 	"tokenlaunch/internal/api"
+	"tokenlaunch/internal/app"
 	"tokenlaunch/internal/config"
+	"tokenlaunch/internal/redis"
 	"tokenlaunch/internal/storage"
 )
 
@@ -23,7 +25,27 @@ func main() {
 	}
 	defer repo.Close()
 
-	server := api.NewServer(repo)
+	rdb, err := redis.New(cfg.Redis.Addr)
+	if err != nil {
+		log.Fatalf("failed to connect to redis: %v", err)
+	}
+	defer rdb.Close()
+
+	publisher, err := app.OpenPublisher(cfg.Queue, cfg.Redis.Addr)
+	if err != nil {
+		log.Fatalf("failed to create queue: %v", err)
+	}
+	defer publisher.Close()
+
+	deadLetters, err := app.OpenDeadLetters(cfg.Queue)
+	if err != nil {
+		log.Fatalf("failed to create dead-letter reader: %v", err)
+	}
+	if deadLetters != nil {
+		defer deadLetters.Close()
+	}
+
+	server := api.NewServer(repo, rdb, publisher, cfg.Server.IngestTokens, cfg.Scraper.DedupTTL, deadLetters)
 
 	go func() {
 		log.Printf("server starting on %s", cfg.Server.Port)
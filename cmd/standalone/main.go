@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"tokenlaunch/internal/api"
+	"tokenlaunch/internal/app"
+	"tokenlaunch/internal/config"
+	"tokenlaunch/internal/queue"
+	"tokenlaunch/internal/redis"
+	"tokenlaunch/internal/storage"
+)
+
+// queueSize is how many scraped messages may wait for the consumer before
+// scrapers block.
+const queueSize = 1000
+
+// standalone runs the scrapers, consumer and server in one process, connected
+// by an in-memory queue instead of Kafka.
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	repo, err := storage.NewPostgres(cfg.Storage.DSN)
+	if err != nil {
+		log.Fatalf("failed to connect to storage: %v", err)
+	}
+	defer repo.Close()
+
+	rdb, err := redis.New(cfg.Redis.Addr)
+	if err != nil {
+		log.Fatalf("failed to connect to redis: %v", err)
+	}
+	defer rdb.Close()
+
+	q := queue.NewMemory(queueSize, queue.RetryPolicy{
+		MaxRetries: cfg.Queue.MaxRetries,
+		Backoff:    cfg.Queue.RetryBackoff,
+	})
+
+	server := api.NewServer(repo, rdb, q, cfg.Server.IngestTokens, cfg.Scraper.DedupTTL, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	app.StartScrapers(ctx, &wg, cfg, q, rdb)
+	app.StartConsumer(ctx, cfg, q, repo, server)
+
+	go func() {
+		log.Printf("server starting on %s", cfg.Server.Port)
+		if err := server.Start(cfg.Server.Port); err != nil {
+			log.Printf("server error: %v", err)
+		}
+	}()
+
+	log.Printf("standalone started")
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	log.Printf("shutting down")
+	cancel()
+	server.Shutdown()
+	wg.Wait()
+	q.Close()
+}
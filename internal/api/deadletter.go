@@ -0,0 +1,61 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"tokenlaunch/internal/queue"
+)
+
+const defaultDeadLetterLimit = 50
+
+func (s *Server) getDeadLetters(c echo.Context) error {
+	if s.deadLetters == nil {
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "dead-letter queue disabled"})
+	}
+
+	limit := defaultDeadLetterLimit
+	if v := c.QueryParam("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+		}
+		limit = n
+	}
+
+	letters, err := s.deadLetters.List(c.Request().Context(), limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if letters == nil {
+		letters = []queue.DeadLetter{}
+	}
+	return c.JSON(http.StatusOK, letters)
+}
+
+func (s *Server) replayDeadLetter(c echo.Context) error {
+	if s.deadLetters == nil {
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "dead-letter queue disabled"})
+	}
+
+	partition, err := strconv.ParseInt(c.Param("partition"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid partition"})
+	}
+	offset, err := strconv.ParseInt(c.Param("offset"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid offset"})
+	}
+
+	err = s.deadLetters.Replay(c.Request().Context(), int32(partition), offset)
+	if errors.Is(err, queue.ErrDeadLetterNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusAccepted, map[string]string{"status": "replayed"})
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"tokenlaunch/internal/domain"
+	"tokenlaunch/internal/scraper"
+)
+
+const maxIngestBatch = 100
+
+// IngestMessage is the JSON schema accepted by POST /api/ingest.
+type IngestMessage struct {
+	Source     string    `json:"source"`
+	ExternalID string    `json:"external_id"`
+	Author     string    `json:"author"`
+	Username   string    `json:"username"`
+	Content    string    `json:"content"`
+	CreatedAt  time.Time `json:"created_at"`
+	URLs       []string  `json:"urls"`
+	Media      []string  `json:"media"`
+}
+
+func (m IngestMessage) toDomain() (domain.Message, error) {
+	source := domain.SourceWebhook
+	if m.Source != "" {
+		source = domain.Source(strings.ToLower(m.Source))
+		if !source.Valid() && source != domain.SourceWebhook {
+			return domain.Message{}, fmt.Errorf("unknown source %q", m.Source)
+		}
+	}
+
+	username := strings.TrimPrefix(strings.TrimSpace(m.Username), "@")
+	switch {
+	case strings.TrimSpace(m.ExternalID) == "":
+		return domain.Message{}, fmt.Errorf("external_id is required")
+	case username == "":
+		return domain.Message{}, fmt.Errorf("username is required")
+	case strings.TrimSpace(m.Content) == "":
+		return domain.Message{}, fmt.Errorf("content is required")
+	}
+
+	createdAt := m.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	author := m.Author
+	if author == "" {
+		author = username
+	}
+
+	// Scrapers derive IDs the same way, so a message that is both pushed
+	// and scraped shares one dedup key and is only queued once. Feed
+	// entries and reposts are the exception, their keys include the
+	// scraped account.
+	msg := domain.Message{
+		ID:         domain.NewID(string(source) + ":" + m.ExternalID),
+		ExternalID: m.ExternalID,
+		Author:     author,
+		Username:   username,
+		Content:    m.Content,
+		Source:     source,
+		CreatedAt:  createdAt,
+		URLs:       m.URLs,
+		Media:      m.Media,
+	}
+	scraper.ExtractEntities(&msg)
+
+	return msg, nil
+}
+
+// ingest accepts one IngestMessage or an array of them and publishes each
+// valid, unseen message to the queue. A batch is rejected as a whole if any
+// message is invalid.
+func (s *Server) ingest(c echo.Context) error {
+	if s.publisher == nil || len(s.ingestTokens) == 0 {
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "ingest disabled"})
+	}
+
+	if !s.authorized(c.Request()) {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	var body json.RawMessage
+	if err := json.NewDecoder(c.Request().Body).Decode(&body); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid JSON: " + err.Error()})
+	}
+
+	var batch []IngestMessage
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(body, &batch); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+	} else {
+		var single IngestMessage
+		if err := json.Unmarshal(body, &single); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		batch = []IngestMessage{single}
+	}
+
+	if len(batch) == 0 || len(batch) > maxIngestBatch {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("batch must contain 1 to %d messages", maxIngestBatch),
+		})
+	}
+
+	messages := make([]domain.Message, len(batch))
+	for i, in := range batch {
+		msg, err := in.toDomain()
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("message %d: %v", i, err)})
+		}
+		messages[i] = msg
+	}
+
+	ctx := c.Request().Context()
+	accepted, duplicates := 0, 0
+	ids := make([]string, 0, len(messages))
+
+	for _, msg := range messages {
+		isNew, err := s.redis.MarkSeen(ctx, msg.ID, s.dedupTTL)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		ids = append(ids, msg.ID)
+		if !isNew {
+			duplicates++
+			continue
+		}
+
+		if err := s.publisher.Publish(ctx, msg); err != nil {
+			if err := s.redis.UnmarkSeen(ctx, msg.ID); err != nil {
+				log.Printf("[ERROR] failed to unmark %s after publish failure: %v", msg.ID, err)
+			}
+			return c.JSON(http.StatusBadGateway, map[string]any{
+				"error":    "publish failed: " + err.Error(),
+				"accepted": accepted,
+			})
+		}
+		accepted++
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]any{
+		"accepted":   accepted,
+		"duplicates": duplicates,
+		"ids":        ids,
+	})
+}
+
+// authorized checks the bearer token against the configured ingest tokens.
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return false
+	}
+
+	for _, allowed := range s.ingestTokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(allowed)) == 1 {
+			return true
+		}
+	}
+	return false
+}
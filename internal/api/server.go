@@ -1,10 +1,12 @@
 package api
 
 import (
+	"context"
 	"embed"
 	"fmt"
 	"html/template"
 	"net/http"
+	"net/url"
 	"strings"
 	"sync"
 	"time"
@@ -12,6 +14,8 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 
+	"tokenlaunch/internal/domain"
+	"tokenlaunch/internal/queue"
 	"tokenlaunch/internal/redis"
 	"tokenlaunch/internal/storage"
 )
@@ -20,11 +24,15 @@ import (
 var templateFS embed.FS
 
 type Server struct {
-	echo      *echo.Echo
-	repo      storage.MessageRepository
-	redis     *redis.Client
-	templates *template.Template
-	sse       *SSEBroker
+	echo         *echo.Echo
+	repo         storage.MessageRepository
+	redis        *redis.Client
+	publisher    queue.Publisher
+	ingestTokens []string
+	dedupTTL     time.Duration
+	deadLetters  *queue.DeadLetters
+	templates    *template.Template
+	sse          *SSEBroker
 }
 
 type SSEBroker struct {
@@ -74,13 +82,26 @@ type MessageView struct {
 	Content        string
 	Classification string
 	TimeAgo        string
+	Type           string
+	Cashtags       []string
+	URLs           []string
 }
 
 type AccountView struct {
+	Source   string
 	Username string
+	Key      string
+	Interval string
+	Priority string
+	Filters  []string
+	Status   string
+	Error    string
 }
 
-func NewServer(repo storage.MessageRepository, rdb *redis.Client) *Server {
+// NewServer creates the dashboard and API server. publisher and ingestTokens
+// enable POST /api/ingest; with either missing the endpoint is disabled.
+// deadLetters enables the /api/dlq endpoints and may be nil.
+func NewServer(repo storage.MessageRepository, rdb *redis.Client, publisher queue.Publisher, ingestTokens []string, dedupTTL time.Duration, deadLetters *queue.DeadLetters) *Server {
 	e := echo.New()
 	e.HideBanner = true
 
@@ -90,12 +111,23 @@ func NewServer(repo storage.MessageRepository, rdb *redis.Client) *Server {
 
 	tmpl := template.Must(template.ParseFS(templateFS, "templates/*.html"))
 
+	var tokens []string
+	for _, t := range ingestTokens {
+		if t = strings.TrimSpace(t); t != "" {
+			tokens = append(tokens, t)
+		}
+	}
+
 	s := &Server{
-		echo:      e,
-		repo:      repo,
-		redis:     rdb,
-		templates: tmpl,
-		sse:       NewSSEBroker(),
+		echo:         e,
+		repo:         repo,
+		redis:        rdb,
+		publisher:    publisher,
+		ingestTokens: tokens,
+		dedupTTL:     dedupTTL,
+		deadLetters:  deadLetters,
+		templates:    tmpl,
+		sse:          NewSSEBroker(),
 	}
 
 	s.routes()
@@ -109,12 +141,25 @@ func (s *Server) routes() {
 	s.echo.GET("/api/stats", s.stats)
 	s.echo.GET("/api/messages", s.getMessages)
 	s.echo.GET("/api/messages/:id", s.getMessage)
+	s.echo.GET("/api/threads/:id", s.getThread)
 	s.echo.GET("/api/events", s.events)
 
 	// Account management
 	s.echo.GET("/api/accounts", s.getAccounts)
 	s.echo.POST("/api/accounts", s.addAccount)
+	s.echo.PUT("/api/accounts/:username", s.updateAccount)
+	s.echo.POST("/api/accounts/:username/reset", s.resetAccount)
 	s.echo.DELETE("/api/accounts/:username", s.removeAccount)
+
+	// External ingestion
+	s.echo.POST("/api/ingest", s.ingest, middleware.BodyLimit("1M"))
+
+	// Dead-letter queue
+	s.echo.GET("/api/dlq", s.getDeadLetters)
+	s.echo.POST("/api/dlq/:partition/:offset/replay", s.replayDeadLetter)
+
+	// Scraper health
+	s.echo.GET("/api/instances", s.getInstances)
 }
 
 func (s *Server) Start(addr string) error {
@@ -131,7 +176,7 @@ func (s *Server) Broadcast(msg string) {
 
 func (s *Server) index(c echo.Context) error {
 	messages, _ := s.repo.FindAll(c.Request().Context(), 20, 0)
-	accounts, _ := s.redis.GetAccounts(c.Request().Context())
+	accounts, _ := s.accounts(c.Request().Context())
 
 	views := make([]MessageView, len(messages))
 	for i, m := range messages {
@@ -141,20 +186,19 @@ func (s *Server) index(c echo.Context) error {
 			Content:        m.Content,
 			Classification: "",
 			TimeAgo:        timeAgo(m.CreatedAt),
+			Type:           string(m.Type()),
+			Cashtags:       m.Cashtags,
+			URLs:           m.URLs,
 		}
 	}
 
-	accountViews := make([]AccountView, len(accounts))
-	for i, a := range accounts {
-		accountViews[i] = AccountView{Username: a}
-	}
-
 	total, launches, endorsements, _ := s.repo.GetStats(c.Request().Context())
 
 	data := map[string]any{
 		"Stats":    Stats{Total: total, Launches: launches, Endorsements: endorsements},
 		"Messages": views,
-		"Accounts": accountViews,
+		"Accounts": accounts,
+		"Sources":  domain.Sources,
 	}
 
 	return s.render(c, "index.html", data)
@@ -193,8 +237,29 @@ func (s *Server) getMessage(c echo.Context) error {
 	return c.JSON(http.StatusOK, msg)
 }
 
+func (s *Server) getThread(c echo.Context) error {
+	id := c.Param("id")
+	thread, err := s.repo.FindThread(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if thread == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+	}
+
+	messages, err := s.repo.FindThreadMessages(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"Thread":   thread,
+		"Messages": messages,
+	})
+}
+
 func (s *Server) getAccounts(c echo.Context) error {
-	accounts, err := s.redis.GetAccounts(c.Request().Context())
+	accounts, err := s.accounts(c.Request().Context())
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
@@ -204,37 +269,201 @@ func (s *Server) getAccounts(c echo.Context) error {
 func (s *Server) addAccount(c echo.Context) error {
 	username := strings.TrimSpace(c.FormValue("username"))
 	username = strings.TrimPrefix(username, "@")
+	source := formSource(c.FormValue("source"))
 
 	if username == "" {
 		return c.HTML(http.StatusBadRequest, `<div class="error">Username required</div>`)
 	}
 
-	exists, _ := s.redis.AccountExists(c.Request().Context(), username)
+	if !source.Valid() {
+		return c.HTML(http.StatusBadRequest, `<div class="error">Unknown source</div>`)
+	}
+
+	if source == domain.SourceFeed {
+		u, err := url.Parse(username)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return c.HTML(http.StatusBadRequest, `<div class="error">Feed must be an http(s) URL</div>`)
+		}
+	}
+
+	settings, err := formSettings(c)
+	if err != nil {
+		return c.HTML(http.StatusBadRequest, `<div class="error">`+template.HTMLEscapeString(err.Error())+`</div>`)
+	}
+
+	exists, _ := s.redis.AccountExists(c.Request().Context(), source, username)
 	if exists {
 		return c.HTML(http.StatusConflict, `<div class="error">Already tracking</div>`)
 	}
 
-	if err := s.redis.AddAccount(c.Request().Context(), username); err != nil {
+	if err := s.redis.AddAccount(c.Request().Context(), source, username); err != nil {
 		return c.HTML(http.StatusInternalServerError, `<div class="error">Failed to add</div>`)
 	}
 
+	if err := s.redis.SetAccountSettings(c.Request().Context(), source, username, settings); err != nil {
+		return c.HTML(http.StatusInternalServerError, `<div class="error">Failed to save settings</div>`)
+	}
+
 	// Return updated account list
-	accounts, _ := s.redis.GetAccounts(c.Request().Context())
+	accounts, _ := s.accounts(c.Request().Context())
+	return s.render(c, "accounts", accounts)
+}
+
+func (s *Server) updateAccount(c echo.Context) error {
+	username := accountParam(c)
+	source := formSource(c.FormValue("source"))
+
+	exists, err := s.redis.AccountExists(c.Request().Context(), source, username)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if !exists {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+	}
+
+	settings, err := formSettings(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := s.redis.SetAccountSettings(c.Request().Context(), source, username, settings); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	accounts, _ := s.accounts(c.Request().Context())
+	return s.render(c, "accounts", accounts)
+}
+
+// resetAccount clears an account's failure state so it is scraped on the
+// next tick, e.g. after a suspended account was renamed back.
+func (s *Server) resetAccount(c echo.Context) error {
+	username := accountParam(c)
+	source := formSource(c.QueryParam("source"))
+
+	if err := s.redis.ClearAccountStatus(c.Request().Context(), source, username); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	accounts, _ := s.accounts(c.Request().Context())
 	return s.render(c, "accounts", accounts)
 }
 
 func (s *Server) removeAccount(c echo.Context) error {
-	username := c.Param("username")
+	username := accountParam(c)
+	source := formSource(c.QueryParam("source"))
 
-	if err := s.redis.RemoveAccount(c.Request().Context(), username); err != nil {
+	if err := s.redis.RemoveAccount(c.Request().Context(), source, username); err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
 	// Return updated account list
-	accounts, _ := s.redis.GetAccounts(c.Request().Context())
+	accounts, _ := s.accounts(c.Request().Context())
 	return s.render(c, "accounts", accounts)
 }
 
+func (s *Server) getInstances(c echo.Context) error {
+	health, err := s.redis.GetInstanceHealth(c.Request().Context(), domain.SourceTwitter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if health == nil {
+		health = []domain.InstanceHealth{}
+	}
+	return c.JSON(http.StatusOK, health)
+}
+
+func (s *Server) accounts(ctx context.Context) ([]AccountView, error) {
+	var views []AccountView
+	for _, source := range domain.Sources {
+		accounts, err := s.redis.GetAccounts(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+		settings, err := s.redis.GetAllAccountSettings(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+		statuses, err := s.redis.GetAllAccountStatus(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range accounts {
+			view := AccountView{Source: string(source), Username: a, Key: url.PathEscape(a)}
+			if st, ok := settings[a]; ok {
+				if st.Interval > 0 {
+					view.Interval = st.Interval.String()
+				}
+				if st.Priority != "" && st.Priority != domain.PriorityNormal {
+					view.Priority = string(st.Priority)
+				}
+				if st.ExcludeRetweets {
+					view.Filters = append(view.Filters, "no RTs")
+				}
+				if st.ExcludeReplies {
+					view.Filters = append(view.Filters, "no replies")
+				}
+			}
+			if st, ok := statuses[a]; ok {
+				view.Status = string(st.State)
+				view.Error = fmt.Sprintf("%d failures, last: %s", st.Failures, st.LastError)
+			}
+			views = append(views, view)
+		}
+	}
+	return views, nil
+}
+
+// formSettings reads the optional interval, priority and filter form fields.
+func formSettings(c echo.Context) (domain.AccountSettings, error) {
+	settings := domain.AccountSettings{
+		Priority:        domain.PriorityNormal,
+		ExcludeRetweets: formBool(c.FormValue("exclude_retweets")),
+		ExcludeReplies:  formBool(c.FormValue("exclude_replies")),
+	}
+
+	if v := strings.TrimSpace(c.FormValue("interval")); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err != nil || interval < time.Second {
+			return settings, fmt.Errorf("invalid interval %q", v)
+		}
+		settings.Interval = interval
+	}
+
+	if v := strings.TrimSpace(c.FormValue("priority")); v != "" {
+		settings.Priority = domain.Priority(strings.ToLower(v))
+		if !settings.Priority.Valid() {
+			return settings, fmt.Errorf("invalid priority %q", v)
+		}
+	}
+
+	return settings, nil
+}
+
+// accountParam returns the :username path parameter. Feed accounts are URLs,
+// so the dashboard path-escapes them.
+func accountParam(c echo.Context) string {
+	username, err := url.PathUnescape(c.Param("username"))
+	if err != nil {
+		return c.Param("username")
+	}
+	return username
+}
+
+func formBool(v string) bool {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "1", "true", "on", "yes":
+		return true
+	}
+	return false
+}
+
+func formSource(v string) domain.Source {
+	if v == "" {
+		return domain.SourceTwitter
+	}
+	return domain.Source(strings.ToLower(v))
+}
+
 func (s *Server) events(c echo.Context) error {
 	c.Response().Header().Set("Content-Type", "text/event-stream")
 	c.Response().Header().Set("Cache-Control", "no-cache")
@@ -0,0 +1,89 @@
+// Package app builds the queues and workers shared by the binaries in cmd
+// from config.
+package app
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"tokenlaunch/internal/classifier"
+	"tokenlaunch/internal/config"
+	"tokenlaunch/internal/notifier"
+	"tokenlaunch/internal/queue"
+	"tokenlaunch/internal/redis"
+	"tokenlaunch/internal/scraper"
+	"tokenlaunch/internal/storage"
+	"tokenlaunch/internal/worker"
+)
+
+// Scrapers returns every configured scraper. Discord and Farcaster are only
+// enabled when their credentials or hub are set.
+func Scrapers(cfg *config.Config, rdb *redis.Client) []scraper.Scraper {
+	scrapers := []scraper.Scraper{
+		scraper.NewNitter(cfg.Scraper.Instances, rdb),
+		scraper.NewTelegram(cfg.Scraper.Telegram.WebURL),
+		scraper.NewFeed(rdb),
+		scraper.NewBluesky(cfg.Scraper.Bluesky.APIURL),
+		scraper.NewReddit(cfg.Scraper.Reddit.APIURL, cfg.Scraper.Reddit.UserAgent, rdb),
+	}
+
+	if cfg.Scraper.Discord.Token != "" {
+		scrapers = append(scrapers, scraper.NewDiscord(cfg.Scraper.Discord.Token, cfg.Scraper.Discord.APIURL))
+	}
+
+	if cfg.Scraper.Farcaster.HubURL != "" {
+		scrapers = append(scrapers, scraper.NewFarcaster(cfg.Scraper.Farcaster.HubURL, cfg.Scraper.Farcaster.APIKey))
+	}
+
+	return scrapers
+}
+
+// StartScrapers runs a worker for every configured scraper until ctx is
+// done, publishing to p, and returns the workers. wg is done once every
+// worker has finished its running fetches, so p can be closed.
+func StartScrapers(ctx context.Context, wg *sync.WaitGroup, cfg *config.Config, p queue.Publisher, rdb *redis.Client) []*worker.Scraper {
+	opts := worker.ScraperOptions{
+		Interval:    cfg.Scraper.Interval,
+		DedupTTL:    cfg.Scraper.DedupTTL,
+		Concurrency: cfg.Scraper.Concurrency,
+		MaxFailures: cfg.Scraper.MaxFailures,
+		MaxBackoff:  cfg.Scraper.MaxBackoff,
+	}
+
+	var workers []*worker.Scraper
+	for _, s := range Scrapers(cfg, rdb) {
+		w := worker.NewScraper(s, p, rdb, opts)
+		workers = append(workers, w)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.Start(ctx)
+		}()
+		log.Printf("%s scraper enabled", s.Source())
+	}
+	return workers
+}
+
+// StartConsumer runs the message consumer and the notification dispatcher
+// that delivers its alerts until ctx is done.
+func StartConsumer(ctx context.Context, cfg *config.Config, c queue.Consumer, repo *storage.Postgres, b worker.Broadcaster) {
+	cl := classifier.NewOpenRouter(cfg.Classifier.APIKey, cfg.Classifier.Model)
+	channels := notifier.TelegramChannels(cfg.Notifier.TelegramToken, cfg.Notifier.TelegramChatIDs)
+	dispatcher := worker.NewDispatcher(repo, channels, worker.DispatcherOptions{
+		Interval:    cfg.Notifier.PollInterval,
+		MaxAttempts: cfg.Notifier.MaxAttempts,
+		Backoff:     cfg.Notifier.RetryBackoff,
+		MaxBackoff:  cfg.Notifier.MaxBackoff,
+	})
+
+	w := worker.NewConsumer(c, repo, cl, dispatcher.Channels(), b)
+
+	go dispatcher.Start(ctx)
+
+	go func() {
+		if err := w.Start(ctx); err != nil {
+			log.Printf("consumer error: %v", err)
+		}
+	}()
+}
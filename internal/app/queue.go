@@ -0,0 +1,93 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+
+	"tokenlaunch/internal/config"
+	"tokenlaunch/internal/queue"
+)
+
+// The memory queue only connects a publisher and consumer in the same
+// process, so each side can't open its own.
+var errMemoryBackend = errors.New("the memory queue backend is only available in cmd/standalone")
+
+// KafkaOptions returns the Kafka client settings of cfg.
+func KafkaOptions(cfg config.QueueConfig) queue.KafkaOptions {
+	return queue.KafkaOptions{
+		ClientID:              cfg.ClientID,
+		Version:               cfg.Version,
+		SASLMechanism:         cfg.SASL.Mechanism,
+		SASLUsername:          cfg.SASL.Username,
+		SASLPassword:          cfg.SASL.Password,
+		TLSEnabled:            cfg.TLS.Enabled,
+		TLSCAFile:             cfg.TLS.CAFile,
+		TLSCertFile:           cfg.TLS.CertFile,
+		TLSKeyFile:            cfg.TLS.KeyFile,
+		TLSInsecureSkipVerify: cfg.TLS.InsecureSkipVerify,
+		RequiredAcks:          cfg.RequiredAcks,
+		InitialOffset:         cfg.InitialOffset,
+		SessionTimeout:        cfg.SessionTimeout,
+		HeartbeatInterval:     cfg.HeartbeatInterval,
+		RebalanceTimeout:      cfg.RebalanceTimeout,
+	}
+}
+
+// OpenPublisher creates the publisher for the configured backend.
+func OpenPublisher(cfg config.QueueConfig, redisAddr string) (queue.Publisher, error) {
+	switch cfg.Backend {
+	case "kafka":
+		return queue.NewKafka(cfg.Brokers, cfg.Topic, KafkaOptions(cfg))
+	case "redis":
+		return queue.NewRedisStream(redisAddr, cfg.Topic, cfg.StreamMaxLen)
+	case "memory":
+		return nil, errMemoryBackend
+	default:
+		return nil, fmt.Errorf("unknown queue backend %q", cfg.Backend)
+	}
+}
+
+// OpenAsyncPublisher is OpenPublisher for producers that can handle delivery
+// failures reported after Publish returned. With the kafka backend and
+// ProducerMode "async" it returns a queue.KafkaAsync, which implements
+// queue.DeliveryReporter.
+func OpenAsyncPublisher(cfg config.QueueConfig, redisAddr string) (queue.Publisher, error) {
+	if cfg.Backend == "kafka" && cfg.ProducerMode == "async" {
+		return queue.NewKafkaAsync(cfg.Brokers, cfg.Topic, KafkaOptions(cfg), queue.AsyncOptions{
+			Compression: cfg.Compression,
+			BatchSize:   cfg.BatchSize,
+			Linger:      cfg.Linger,
+		})
+	}
+	return OpenPublisher(cfg, redisAddr)
+}
+
+// OpenConsumer creates the consumer for the configured backend.
+func OpenConsumer(cfg config.QueueConfig, redisAddr string) (queue.Consumer, error) {
+	retry := queue.RetryPolicy{
+		MaxRetries:      cfg.MaxRetries,
+		Backoff:         cfg.RetryBackoff,
+		DeadLetterTopic: cfg.DLQTopic,
+	}
+
+	switch cfg.Backend {
+	case "kafka":
+		return queue.NewKafkaConsumer(cfg.Brokers, cfg.GroupID, cfg.Topic, KafkaOptions(cfg), retry, cfg.Workers)
+	case "redis":
+		return queue.NewRedisStreamConsumer(redisAddr, cfg.GroupID, cfg.Topic, retry)
+	case "memory":
+		return nil, errMemoryBackend
+	default:
+		return nil, fmt.Errorf("unknown queue backend %q", cfg.Backend)
+	}
+}
+
+// OpenDeadLetters creates the dead-letter reader for the configured backend.
+// It returns nil for the redis backend, whose dead letters are plain stream
+// entries to be inspected with XRANGE.
+func OpenDeadLetters(cfg config.QueueConfig) (*queue.DeadLetters, error) {
+	if cfg.Backend != "kafka" {
+		return nil, nil
+	}
+	return queue.NewDeadLetters(cfg.Brokers, cfg.DLQTopic, KafkaOptions(cfg))
+}
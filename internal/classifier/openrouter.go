@@ -31,7 +31,7 @@ func (o *OpenRouter) Classify(ctx context.Context, msg domain.Message) (*Result,
 
 Tweet by @%s:
 "%s"
-
+%s
 Classify as one of:
 - "launch": Announces a new crypto token launch
 - "endorsement": Promotes or endorses an existing crypto token
@@ -43,7 +43,7 @@ Respond in JSON format only:
   "token": "token symbol if mentioned, empty otherwise",
   "confidence": 0.0-1.0,
   "reason": "brief explanation"
-}`, msg.Username, msg.Content)
+}`, msg.Username, msg.Content, describeEntities(msg))
 
 	reqBody := map[string]any{
 		"model": o.model,
@@ -94,6 +94,38 @@ Respond in JSON format only:
 	return parseResponse(apiResp.Choices[0].Message.Content)
 }
 
+// describeEntities lists the structured parts of a post the LLM would
+// otherwise not see, such as outbound links and the quoted tweet.
+func describeEntities(msg domain.Message) string {
+	var b strings.Builder
+	switch {
+	case msg.IsRetweet && msg.OriginalAuthor != "":
+		b.WriteString("\nThis is a retweet of @" + msg.OriginalAuthor + ".")
+	case msg.IsRetweet:
+		b.WriteString("\nThis is a retweet.")
+	case msg.IsReply && msg.InReplyTo != "":
+		b.WriteString("\nThis is a reply to @" + msg.InReplyTo + ".")
+	case msg.IsReply:
+		b.WriteString("\nThis is a reply.")
+	}
+	if len(msg.Cashtags) > 0 {
+		b.WriteString("\nCashtags: $" + strings.Join(msg.Cashtags, ", $"))
+	}
+	if len(msg.URLs) > 0 {
+		b.WriteString("\nLinks: " + strings.Join(msg.URLs, ", "))
+	}
+	if msg.QuotedURL != "" {
+		b.WriteString("\nQuotes: " + msg.QuotedURL)
+	}
+	if len(msg.Media) > 0 {
+		fmt.Fprintf(&b, "\nAttached media: %d", len(msg.Media))
+	}
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
 func parseResponse(content string) (*Result, error) {
 	content = strings.TrimSpace(content)
 	content = strings.TrimPrefix(content, "```json")
@@ -21,12 +21,45 @@ type Config struct {
 }
 
 type ServerConfig struct {
-	Port string
+	Port         string
+	IngestTokens []string
 }
 
 type ScraperConfig struct {
-	Instance string
-	Interval time.Duration
+	Instances   []string
+	Interval    time.Duration
+	DedupTTL    time.Duration
+	Concurrency int
+	MaxFailures int
+	MaxBackoff  time.Duration
+	Discord     DiscordConfig
+	Telegram    TelegramConfig
+	Bluesky     BlueskyConfig
+	Reddit      RedditConfig
+	Farcaster   FarcasterConfig
+}
+
+type DiscordConfig struct {
+	Token  string
+	APIURL string
+}
+
+type TelegramConfig struct {
+	WebURL string
+}
+
+type BlueskyConfig struct {
+	APIURL string
+}
+
+type RedditConfig struct {
+	APIURL    string
+	UserAgent string
+}
+
+type FarcasterConfig struct {
+	HubURL string
+	APIKey string
 }
 
 type RedisConfig struct {
@@ -34,9 +67,49 @@ type RedisConfig struct {
 }
 
 type QueueConfig struct {
-	Brokers []string
-	Topic   string
-	GroupID string
+	// Backend is "kafka" (default) or "redis" for Redis Streams on Redis.Addr.
+	Backend      string
+	Brokers      []string
+	Topic        string
+	GroupID      string
+	DLQTopic     string
+	MaxRetries   int
+	RetryBackoff time.Duration
+	// Workers is how many messages per partition are handled concurrently.
+	Workers int
+	// ProducerMode "async" makes the scraper publish through a batching
+	// producer; anything else publishes synchronously.
+	ProducerMode string
+	Compression  string
+	BatchSize    int
+	Linger       time.Duration
+	// StreamMaxLen roughly caps the Redis stream length.
+	StreamMaxLen int64
+
+	// Kafka client settings
+	ClientID          string
+	Version           string
+	RequiredAcks      string
+	InitialOffset     string
+	SessionTimeout    time.Duration
+	HeartbeatInterval time.Duration
+	RebalanceTimeout  time.Duration
+	SASL              SASLConfig
+	TLS               TLSConfig
+}
+
+type SASLConfig struct {
+	Mechanism string
+	Username  string
+	Password  string
+}
+
+type TLSConfig struct {
+	Enabled            bool
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	InsecureSkipVerify bool
 }
 
 type StorageConfig struct {
@@ -51,6 +124,10 @@ type ClassifierConfig struct {
 type NotifierConfig struct {
 	TelegramToken   string
 	TelegramChatIDs []string
+	PollInterval    time.Duration
+	MaxAttempts     int
+	RetryBackoff    time.Duration
+	MaxBackoff      time.Duration
 }
 
 func Load() (*Config, error) {
@@ -70,15 +147,86 @@ func Load() (*Config, error) {
 	cfg := &Config{}
 
 	cfg.Server.Port = k.String("server.port")
+	cfg.Server.IngestTokens = strings.Split(k.String("server.ingest.tokens"), ",")
 
-	cfg.Scraper.Instance = k.String("scraper.instance")
+	cfg.Scraper.Instances = strings.Split(k.String("scraper.instance"), ",")
 	cfg.Scraper.Interval = k.Duration("scraper.interval")
+	cfg.Scraper.DedupTTL = k.Duration("scraper.dedup.ttl")
+	if cfg.Scraper.DedupTTL == 0 {
+		cfg.Scraper.DedupTTL = 72 * time.Hour
+	}
+	cfg.Scraper.Concurrency = k.Int("scraper.concurrency")
+	if cfg.Scraper.Concurrency == 0 {
+		cfg.Scraper.Concurrency = 8
+	}
+	cfg.Scraper.MaxFailures = k.Int("scraper.max.failures")
+	cfg.Scraper.MaxBackoff = k.Duration("scraper.max.backoff")
+	cfg.Scraper.Discord.Token = k.String("scraper.discord.token")
+	cfg.Scraper.Discord.APIURL = k.String("scraper.discord.api.url")
+	cfg.Scraper.Telegram.WebURL = k.String("scraper.telegram.web.url")
+	cfg.Scraper.Bluesky.APIURL = k.String("scraper.bluesky.api.url")
+	cfg.Scraper.Reddit.APIURL = k.String("scraper.reddit.api.url")
+	cfg.Scraper.Reddit.UserAgent = k.String("scraper.reddit.user.agent")
+	cfg.Scraper.Farcaster.HubURL = k.String("scraper.farcaster.hub.url")
+	cfg.Scraper.Farcaster.APIKey = k.String("scraper.farcaster.api.key")
 
 	cfg.Redis.Addr = k.String("redis.addr")
 
+	cfg.Queue.Backend = k.String("queue.backend")
+	if cfg.Queue.Backend == "" {
+		cfg.Queue.Backend = "kafka"
+	}
 	cfg.Queue.Brokers = strings.Split(k.String("queue.brokers"), ",")
 	cfg.Queue.Topic = k.String("queue.topic")
 	cfg.Queue.GroupID = k.String("queue.group.id")
+	cfg.Queue.DLQTopic = k.String("queue.dlq.topic")
+	if cfg.Queue.DLQTopic == "" {
+		cfg.Queue.DLQTopic = cfg.Queue.Topic + ".dlq"
+	}
+	cfg.Queue.MaxRetries = 3
+	if k.Exists("queue.max.retries") {
+		cfg.Queue.MaxRetries = k.Int("queue.max.retries")
+	}
+	cfg.Queue.RetryBackoff = k.Duration("queue.retry.backoff")
+	if cfg.Queue.RetryBackoff == 0 {
+		cfg.Queue.RetryBackoff = time.Second
+	}
+	cfg.Queue.Workers = k.Int("queue.workers")
+	if cfg.Queue.Workers == 0 {
+		cfg.Queue.Workers = 4
+	}
+	cfg.Queue.ProducerMode = k.String("queue.producer.mode")
+	cfg.Queue.Compression = k.String("queue.compression")
+	cfg.Queue.BatchSize = k.Int("queue.batch.size")
+	if cfg.Queue.BatchSize == 0 {
+		cfg.Queue.BatchSize = 100
+	}
+	cfg.Queue.Linger = k.Duration("queue.linger")
+	if cfg.Queue.Linger == 0 {
+		cfg.Queue.Linger = 50 * time.Millisecond
+	}
+	cfg.Queue.StreamMaxLen = k.Int64("queue.stream.maxlen")
+	if cfg.Queue.StreamMaxLen == 0 {
+		cfg.Queue.StreamMaxLen = 100000
+	}
+	cfg.Queue.ClientID = k.String("queue.client.id")
+	if cfg.Queue.ClientID == "" {
+		cfg.Queue.ClientID = "tokenlaunch"
+	}
+	cfg.Queue.Version = k.String("queue.version")
+	cfg.Queue.RequiredAcks = k.String("queue.required.acks")
+	cfg.Queue.InitialOffset = k.String("queue.initial.offset")
+	cfg.Queue.SessionTimeout = k.Duration("queue.session.timeout")
+	cfg.Queue.HeartbeatInterval = k.Duration("queue.heartbeat.interval")
+	cfg.Queue.RebalanceTimeout = k.Duration("queue.rebalance.timeout")
+	cfg.Queue.SASL.Mechanism = k.String("queue.sasl.mechanism")
+	cfg.Queue.SASL.Username = k.String("queue.sasl.username")
+	cfg.Queue.SASL.Password = k.String("queue.sasl.password")
+	cfg.Queue.TLS.Enabled = k.Bool("queue.tls.enabled")
+	cfg.Queue.TLS.CAFile = k.String("queue.tls.ca.file")
+	cfg.Queue.TLS.CertFile = k.String("queue.tls.cert.file")
+	cfg.Queue.TLS.KeyFile = k.String("queue.tls.key.file")
+	cfg.Queue.TLS.InsecureSkipVerify = k.Bool("queue.tls.insecure.skip.verify")
 
 	cfg.Storage.DSN = k.String("storage.dsn")
 
@@ -87,6 +235,10 @@ func Load() (*Config, error) {
 
 	cfg.Notifier.TelegramToken = k.String("notifier.telegram.token")
 	cfg.Notifier.TelegramChatIDs = strings.Split(k.String("notifier.telegram.chat.ids"), ",")
+	cfg.Notifier.PollInterval = k.Duration("notifier.poll.interval")
+	cfg.Notifier.MaxAttempts = k.Int("notifier.max.attempts")
+	cfg.Notifier.RetryBackoff = k.Duration("notifier.retry.backoff")
+	cfg.Notifier.MaxBackoff = k.Duration("notifier.max.backoff")
 
 	return cfg, nil
 }
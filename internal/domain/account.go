@@ -0,0 +1,64 @@
+package domain
+
+import "time"
+
+type Priority string
+
+const (
+	PriorityHigh   Priority = "high"
+	PriorityNormal Priority = "normal"
+	PriorityLow    Priority = "low"
+)
+
+// Rank orders priorities for dispatch; higher goes first.
+func (p Priority) Rank() int {
+	switch p {
+	case PriorityHigh:
+		return 2
+	case PriorityLow:
+		return 0
+	default:
+		return 1
+	}
+}
+
+func (p Priority) Valid() bool {
+	return p == PriorityHigh || p == PriorityNormal || p == PriorityLow
+}
+
+// AccountSettings are per-account scrape options. A zero Interval means the
+// scraper's default interval applies.
+type AccountSettings struct {
+	Interval        time.Duration
+	Priority        Priority
+	ExcludeRetweets bool
+	ExcludeReplies  bool
+}
+
+// Allows reports whether a scraped message passes the account's filters.
+func (s AccountSettings) Allows(msg Message) bool {
+	if s.ExcludeRetweets && msg.IsRetweet {
+		return false
+	}
+	if s.ExcludeReplies && msg.IsReply {
+		return false
+	}
+	return true
+}
+
+type AccountState string
+
+const (
+	AccountBackoff   AccountState = "backoff"
+	AccountSuspended AccountState = "suspended"
+)
+
+// AccountStatus is the scraper's runtime view of a failing account. It is
+// cleared once the account is scraped successfully again.
+type AccountStatus struct {
+	State       AccountState
+	Failures    int
+	LastError   string
+	LastFailure time.Time
+	NextAttempt time.Time
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// Cursor remembers how far a source has been read for one account.
+type Cursor struct {
+	LastID        string
+	LastPublished time.Time
+	Instance      string
+	ETag          string
+	LastModified  string
+	// SeenIDs are the items of the last read, for feeds that aren't in time
+	// order and can't be cut off at LastPublished.
+	SeenIDs []string `json:",omitempty"`
+}
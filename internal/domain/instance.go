@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+type InstanceHealth struct {
+	Instance      string
+	Healthy       bool
+	Score         float64
+	Requests      int
+	Successes     int
+	Failures      int
+	RateLimited   int
+	SuccessRate   float64
+	Latency       time.Duration
+	CooldownUntil time.Time
+	LastError     string
+	LastSuccess   time.Time
+}
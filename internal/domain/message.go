@@ -1,6 +1,11 @@
 package domain
 
-import "time"
+import (
+	"crypto/md5"
+	"fmt"
+	"strings"
+	"time"
+)
 
 type Message struct {
 	ID         string
@@ -10,12 +15,88 @@ type Message struct {
 	Content    string
 	Source     Source
 	CreatedAt  time.Time
+
+	URLs      []string
+	Cashtags  []string
+	Mentions  []string
+	Hashtags  []string
+	Media     []string
+	IsRetweet bool
+	IsReply   bool
+	QuotedURL string
+
+	// OriginalAuthor is the author of a retweeted post; InReplyTo is the
+	// username a reply answers.
+	OriginalAuthor string
+	InReplyTo      string
+
+	// ThreadID links self-replies to the first message of their thread.
+	ThreadID string
+}
+
+// NewID derives a short stable message ID from a source-unique key.
+func NewID(key string) string {
+	hash := md5.Sum([]byte(key))
+	return fmt.Sprintf("%x", hash)[:12]
+}
+
+func (m Message) IsSelfReply() bool {
+	return m.IsReply && m.InReplyTo != "" && strings.EqualFold(m.InReplyTo, m.Username)
+}
+
+type PostType string
+
+const (
+	PostOriginal PostType = "original"
+	PostRetweet  PostType = "retweet"
+	PostReply    PostType = "reply"
+	PostQuote    PostType = "quote"
+)
+
+func (m Message) Type() PostType {
+	switch {
+	case m.IsRetweet:
+		return PostRetweet
+	case m.IsReply:
+		return PostReply
+	case m.QuotedURL != "":
+		return PostQuote
+	default:
+		return PostOriginal
+	}
 }
 
 type Source string
 
 const (
-	SourceTwitter  Source = "twitter"
-	SourceDiscord  Source = "discord"
-	SourceTelegram Source = "telegram"
+	SourceTwitter   Source = "twitter"
+	SourceDiscord   Source = "discord"
+	SourceTelegram  Source = "telegram"
+	SourceFeed      Source = "feed"
+	SourceBluesky   Source = "bluesky"
+	SourceReddit    Source = "reddit"
+	SourceFarcaster Source = "farcaster"
 )
+
+// SourceWebhook marks messages pushed through the ingest API. It is not
+// scraped, so it is not part of Sources.
+const SourceWebhook Source = "webhook"
+
+var Sources = []Source{
+	SourceTwitter,
+	SourceDiscord,
+	SourceTelegram,
+	SourceFeed,
+	SourceBluesky,
+	SourceReddit,
+	SourceFarcaster,
+}
+
+func (s Source) Valid() bool {
+	for _, src := range Sources {
+		if s == src {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+type OutboxStatus string
+
+const (
+	OutboxPending OutboxStatus = "pending"
+	OutboxSent    OutboxStatus = "sent"
+	// OutboxFailed entries ran out of attempts and are no longer retried.
+	OutboxFailed OutboxStatus = "failed"
+)
+
+// OutboxEntry is a notification waiting to be delivered to one channel.
+type OutboxEntry struct {
+	ID            int64
+	MessageID     string
+	Channel       string
+	Payload       []byte
+	Status        OutboxStatus
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	SentAt        *time.Time
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+// ProcessingStage is how far the consumer got with a message. Stages are
+// completed in order, so a redelivered message resumes after the last one.
+type ProcessingStage string
+
+const (
+	StageReceived   ProcessingStage = "received"
+	StageClassified ProcessingStage = "classified"
+	StageNotified   ProcessingStage = "notified"
+)
+
+func (s ProcessingStage) rank() int {
+	switch s {
+	case StageReceived:
+		return 1
+	case StageClassified:
+		return 2
+	case StageNotified:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Reached reports whether stage has been completed.
+func (s ProcessingStage) Reached(stage ProcessingStage) bool {
+	return s.rank() >= stage.rank()
+}
+
+// ProcessingState records the consumer's progress on one message, with the
+// classification result so later stages can resume without the classifier.
+type ProcessingState struct {
+	MessageID      string
+	Stage          ProcessingStage
+	Classification string
+	Token          string
+	Confidence     float64
+	Reason         string
+	// Alert is whether the classification warrants a notification.
+	Alert     bool
+	UpdatedAt time.Time
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// Thread groups consecutive self-replies by one author. Its ID is the ID of
+// the first message.
+type Thread struct {
+	ID             string
+	Source         Source
+	Username       string
+	Classification string
+	Token          string
+	Confidence     float64
+	StartedAt      time.Time
+	UpdatedAt      time.Time
+}
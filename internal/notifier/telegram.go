@@ -23,6 +23,19 @@ func NewTelegram(botToken string, chatIDs []string) *Telegram {
 	}
 }
 
+// TelegramChannels returns one notifier per chat, keyed "telegram:<chat id>",
+// so delivery to each chat is tracked and retried on its own.
+func TelegramChannels(botToken string, chatIDs []string) map[string]Notifier {
+	channels := make(map[string]Notifier)
+	for _, chatID := range chatIDs {
+		if chatID == "" {
+			continue
+		}
+		channels["telegram:"+chatID] = NewTelegram(botToken, []string{chatID})
+	}
+	return channels
+}
+
 func (t *Telegram) Notify(ctx context.Context, n Notification) error {
 	text := formatMessage(n)
 
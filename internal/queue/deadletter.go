@@ -0,0 +1,237 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+const (
+	headerError     = "dlq-error"
+	headerAttempts  = "dlq-attempts"
+	headerTopic     = "dlq-original-topic"
+	headerPartition = "dlq-original-partition"
+	headerOffset    = "dlq-original-offset"
+	headerFailedAt  = "dlq-failed-at"
+)
+
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// DeadLetter is a message that exhausted its retries, with the raw payload
+// and the error metadata recorded when it was dead-lettered.
+type DeadLetter struct {
+	Partition         int32
+	Offset            int64
+	Key               string
+	Payload           string
+	Error             string
+	Attempts          int
+	OriginalTopic     string
+	OriginalPartition int32
+	OriginalOffset    int64
+	FailedAt          time.Time
+	SchemaVersion     int
+	TraceID           string
+}
+
+// DeadLetters reads and replays the dead-letter topic.
+type DeadLetters struct {
+	client   sarama.Client
+	producer sarama.SyncProducer
+	topic    string
+}
+
+func NewDeadLetters(brokers []string, topic string, opts KafkaOptions) (*DeadLetters, error) {
+	config, err := opts.config()
+	if err != nil {
+		return nil, err
+	}
+	config.Producer.Return.Successes = true
+
+	client, err := sarama.NewClient(brokers, config)
+	if err != nil {
+		return nil, err
+	}
+
+	producer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return &DeadLetters{
+		client:   client,
+		producer: producer,
+		topic:    topic,
+	}, nil
+}
+
+func (d *DeadLetters) Close() error {
+	if err := d.producer.Close(); err != nil {
+		d.client.Close()
+		return err
+	}
+	return d.client.Close()
+}
+
+// List returns up to limit of the most recent dead letters of each partition.
+func (d *DeadLetters) List(ctx context.Context, limit int) ([]DeadLetter, error) {
+	partitions, err := d.client.Partitions(d.topic)
+	if err != nil {
+		return nil, err
+	}
+
+	consumer, err := sarama.NewConsumerFromClient(d.client)
+	if err != nil {
+		return nil, err
+	}
+	defer consumer.Close()
+
+	var letters []DeadLetter
+	for _, partition := range partitions {
+		oldest, err := d.client.GetOffset(d.topic, partition, sarama.OffsetOldest)
+		if err != nil {
+			return nil, err
+		}
+		newest, err := d.client.GetOffset(d.topic, partition, sarama.OffsetNewest)
+		if err != nil {
+			return nil, err
+		}
+
+		start := newest - int64(limit)
+		if start < oldest {
+			start = oldest
+		}
+		if start >= newest {
+			continue
+		}
+
+		msgs, err := readRange(ctx, consumer, d.topic, partition, start, newest)
+		if err != nil {
+			return nil, err
+		}
+		for _, msg := range msgs {
+			letters = append(letters, toDeadLetter(msg))
+		}
+	}
+
+	return letters, nil
+}
+
+// Replay republishes one dead letter to the topic it originally came from.
+func (d *DeadLetters) Replay(ctx context.Context, partition int32, offset int64) error {
+	consumer, err := sarama.NewConsumerFromClient(d.client)
+	if err != nil {
+		return err
+	}
+	defer consumer.Close()
+
+	newest, err := d.client.GetOffset(d.topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return err
+	}
+	if offset >= newest {
+		return ErrDeadLetterNotFound
+	}
+
+	msgs, err := readRange(ctx, consumer, d.topic, partition, offset, offset+1)
+	if err != nil {
+		return err
+	}
+	if len(msgs) == 0 || msgs[0].Offset != offset {
+		return ErrDeadLetterNotFound
+	}
+
+	letter := toDeadLetter(msgs[0])
+	if letter.OriginalTopic == "" {
+		return fmt.Errorf("dead letter %d/%d has no original topic", partition, offset)
+	}
+
+	_, _, err = d.producer.SendMessage(&sarama.ProducerMessage{
+		Topic:   letter.OriginalTopic,
+		Key:     sarama.ByteEncoder(msgs[0].Key),
+		Value:   sarama.ByteEncoder(msgs[0].Value),
+		Headers: envelopeHeaders(msgs[0].Headers),
+	})
+	return err
+}
+
+// readRange reads offsets [from, to) of a partition.
+func readRange(ctx context.Context, consumer sarama.Consumer, topic string, partition int32, from, to int64) ([]*sarama.ConsumerMessage, error) {
+	pc, err := consumer.ConsumePartition(topic, partition, from)
+	if err != nil {
+		return nil, err
+	}
+	defer pc.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var msgs []*sarama.ConsumerMessage
+	for {
+		select {
+		case <-ctx.Done():
+			return msgs, ctx.Err()
+		case err := <-pc.Errors():
+			return msgs, err
+		case msg := <-pc.Messages():
+			msgs = append(msgs, msg)
+			if msg.Offset+1 >= to {
+				return msgs, nil
+			}
+		}
+	}
+}
+
+// envelopeHeaders copies a record's headers without the dead-letter metadata.
+func envelopeHeaders(headers []*sarama.RecordHeader) []sarama.RecordHeader {
+	var out []sarama.RecordHeader
+	for _, h := range headers {
+		if strings.HasPrefix(string(h.Key), "dlq-") {
+			continue
+		}
+		out = append(out, *h)
+	}
+	return out
+}
+
+func toDeadLetter(msg *sarama.ConsumerMessage) DeadLetter {
+	letter := DeadLetter{
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Key:       string(msg.Key),
+		Payload:   string(msg.Value),
+		// Headerless records predate the envelope
+		SchemaVersion: 1,
+	}
+
+	for _, h := range msg.Headers {
+		v := string(h.Value)
+		switch string(h.Key) {
+		case headerError:
+			letter.Error = v
+		case headerAttempts:
+			letter.Attempts, _ = strconv.Atoi(v)
+		case headerTopic:
+			letter.OriginalTopic = v
+		case headerPartition:
+			p, _ := strconv.ParseInt(v, 10, 32)
+			letter.OriginalPartition = int32(p)
+		case headerOffset:
+			letter.OriginalOffset, _ = strconv.ParseInt(v, 10, 64)
+		case headerFailedAt:
+			letter.FailedAt, _ = time.Parse(time.RFC3339, v)
+		case headerSchemaVersion:
+			letter.SchemaVersion, _ = strconv.Atoi(v)
+		case headerTraceID:
+			letter.TraceID = v
+		}
+	}
+
+	return letter
+}
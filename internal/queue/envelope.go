@@ -0,0 +1,217 @@
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"tokenlaunch/internal/domain"
+)
+
+// SchemaVersion is the payload version written by this build. Version 1 is
+// the headerless json.Marshal(domain.Message) published by older builds.
+const SchemaVersion = 2
+
+const EventMessageCreated = "message.created"
+
+const (
+	headerSchemaVersion = "schema-version"
+	headerEventType     = "event-type"
+	headerProducedAt    = "produced-at"
+	headerProducerID    = "producer-id"
+	headerTraceID       = "trace-id"
+)
+
+// Envelope is the metadata carried in Kafka headers around a message payload.
+type Envelope struct {
+	SchemaVersion int
+	EventType     string
+	ProducedAt    time.Time
+	ProducerID    string
+	TraceID       string
+	Message       domain.Message
+}
+
+// messageV2 is the version 2 payload. It is decoupled from domain.Message so
+// changing the domain type doesn't silently change the wire format; bump
+// SchemaVersion and add a decoder instead.
+type messageV2 struct {
+	ID             string    `json:"id"`
+	ExternalID     string    `json:"external_id"`
+	Author         string    `json:"author"`
+	Username       string    `json:"username"`
+	Content        string    `json:"content"`
+	Source         string    `json:"source"`
+	CreatedAt      time.Time `json:"created_at"`
+	URLs           []string  `json:"urls,omitempty"`
+	Cashtags       []string  `json:"cashtags,omitempty"`
+	Mentions       []string  `json:"mentions,omitempty"`
+	Hashtags       []string  `json:"hashtags,omitempty"`
+	Media          []string  `json:"media,omitempty"`
+	IsRetweet      bool      `json:"is_retweet,omitempty"`
+	IsReply        bool      `json:"is_reply,omitempty"`
+	QuotedURL      string    `json:"quoted_url,omitempty"`
+	OriginalAuthor string    `json:"original_author,omitempty"`
+	InReplyTo      string    `json:"in_reply_to,omitempty"`
+	ThreadID       string    `json:"thread_id,omitempty"`
+}
+
+func encodeV2(msg domain.Message) ([]byte, error) {
+	return json.Marshal(messageV2{
+		ID:             msg.ID,
+		ExternalID:     msg.ExternalID,
+		Author:         msg.Author,
+		Username:       msg.Username,
+		Content:        msg.Content,
+		Source:         string(msg.Source),
+		CreatedAt:      msg.CreatedAt,
+		URLs:           msg.URLs,
+		Cashtags:       msg.Cashtags,
+		Mentions:       msg.Mentions,
+		Hashtags:       msg.Hashtags,
+		Media:          msg.Media,
+		IsRetweet:      msg.IsRetweet,
+		IsReply:        msg.IsReply,
+		QuotedURL:      msg.QuotedURL,
+		OriginalAuthor: msg.OriginalAuthor,
+		InReplyTo:      msg.InReplyTo,
+		ThreadID:       msg.ThreadID,
+	})
+}
+
+func decodeV2(data []byte) (domain.Message, error) {
+	var m messageV2
+	if err := json.Unmarshal(data, &m); err != nil {
+		return domain.Message{}, err
+	}
+	return domain.Message{
+		ID:             m.ID,
+		ExternalID:     m.ExternalID,
+		Author:         m.Author,
+		Username:       m.Username,
+		Content:        m.Content,
+		Source:         domain.Source(m.Source),
+		CreatedAt:      m.CreatedAt,
+		URLs:           m.URLs,
+		Cashtags:       m.Cashtags,
+		Mentions:       m.Mentions,
+		Hashtags:       m.Hashtags,
+		Media:          m.Media,
+		IsRetweet:      m.IsRetweet,
+		IsReply:        m.IsReply,
+		QuotedURL:      m.QuotedURL,
+		OriginalAuthor: m.OriginalAuthor,
+		InReplyTo:      m.InReplyTo,
+		ThreadID:       m.ThreadID,
+	}, nil
+}
+
+// decodeV1 reads payloads from before the envelope, which were the domain
+// type with Go's default field names.
+func decodeV1(data []byte) (domain.Message, error) {
+	var msg domain.Message
+	err := json.Unmarshal(data, &msg)
+	return msg, err
+}
+
+var decoders = map[int]func([]byte) (domain.Message, error){
+	1: decodeV1,
+	2: decodeV2,
+}
+
+// encodeEnvelope builds the headers and current-version payload for msg.
+func encodeEnvelope(ctx context.Context, producerID string, msg domain.Message) ([]sarama.RecordHeader, []byte, error) {
+	data, err := encodeV2(msg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	traceID := TraceID(ctx)
+	if traceID == "" {
+		traceID = newTraceID()
+	}
+
+	headers := []sarama.RecordHeader{
+		{Key: []byte(headerSchemaVersion), Value: []byte(strconv.Itoa(SchemaVersion))},
+		{Key: []byte(headerEventType), Value: []byte(EventMessageCreated)},
+		{Key: []byte(headerProducedAt), Value: []byte(time.Now().UTC().Format(time.RFC3339Nano))},
+		{Key: []byte(headerProducerID), Value: []byte(producerID)},
+		{Key: []byte(headerTraceID), Value: []byte(traceID)},
+	}
+	return headers, data, nil
+}
+
+// decodeEnvelope reads a record of any known schema version. Records without
+// a version header are version 1.
+func decodeEnvelope(headers []*sarama.RecordHeader, data []byte) (Envelope, error) {
+	env := Envelope{SchemaVersion: 1}
+
+	for _, h := range headers {
+		v := string(h.Value)
+		switch string(h.Key) {
+		case headerSchemaVersion:
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return env, fmt.Errorf("invalid schema version %q", v)
+			}
+			env.SchemaVersion = n
+		case headerEventType:
+			env.EventType = v
+		case headerProducedAt:
+			env.ProducedAt, _ = time.Parse(time.RFC3339Nano, v)
+		case headerProducerID:
+			env.ProducerID = v
+		case headerTraceID:
+			env.TraceID = v
+		}
+	}
+
+	if env.EventType != "" && env.EventType != EventMessageCreated {
+		return env, fmt.Errorf("unknown event type %q", env.EventType)
+	}
+
+	decode, ok := decoders[env.SchemaVersion]
+	if !ok {
+		return env, fmt.Errorf("unsupported schema version %d", env.SchemaVersion)
+	}
+
+	msg, err := decode(data)
+	if err != nil {
+		return env, fmt.Errorf("decode v%d payload: %w", env.SchemaVersion, err)
+	}
+	env.Message = msg
+
+	return env, nil
+}
+
+type traceKey struct{}
+
+// WithTraceID makes Publish carry id instead of a generated trace ID.
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceKey{}, id)
+}
+
+func TraceID(ctx context.Context) string {
+	id, _ := ctx.Value(traceKey{}).(string)
+	return id
+}
+
+func newTraceID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// defaultProducerID identifies the publishing process, e.g. "scraper@host".
+func defaultProducerID() string {
+	host, _ := os.Hostname()
+	return filepath.Base(os.Args[0]) + "@" + host
+}
@@ -2,7 +2,10 @@ package queue
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
 
 	"github.com/IBM/sarama"
 
@@ -10,12 +13,16 @@ import (
 )
 
 type Kafka struct {
-	producer sarama.SyncProducer
-	topic    string
+	producer   sarama.SyncProducer
+	topic      string
+	producerID string
 }
 
-func NewKafka(brokers []string, topic string) (*Kafka, error) {
-	config := sarama.NewConfig()
+func NewKafka(brokers []string, topic string, opts KafkaOptions) (*Kafka, error) {
+	config, err := opts.config()
+	if err != nil {
+		return nil, err
+	}
 	config.Producer.Return.Successes = true
 
 	producer, err := sarama.NewSyncProducer(brokers, config)
@@ -24,21 +31,24 @@ func NewKafka(brokers []string, topic string) (*Kafka, error) {
 	}
 
 	return &Kafka{
-		producer: producer,
-		topic:    topic,
+		producer:   producer,
+		topic:      topic,
+		producerID: defaultProducerID(),
 	}, nil
 }
 
 func (k *Kafka) Publish(ctx context.Context, msg domain.Message) error {
-	data, err := json.Marshal(msg)
+	headers, data, err := encodeEnvelope(ctx, k.producerID, msg)
 	if err != nil {
 		return err
 	}
 
+	// Keyed by account so one author's messages stay ordered on one partition
 	_, _, err = k.producer.SendMessage(&sarama.ProducerMessage{
-		Topic: k.topic,
-		Key:   sarama.StringEncoder(msg.ID),
-		Value: sarama.ByteEncoder(data),
+		Topic:   k.topic,
+		Key:     sarama.StringEncoder(string(msg.Source) + ":" + msg.Username),
+		Value:   sarama.ByteEncoder(data),
+		Headers: headers,
 	})
 
 	return err
@@ -50,23 +60,48 @@ func (k *Kafka) Close() error {
 
 type KafkaConsumer struct {
 	group   sarama.ConsumerGroup
+	dlq     sarama.SyncProducer
 	topic   string
+	retry   RetryPolicy
+	workers int
 	handler func(msg domain.Message) error
 }
 
-func NewKafkaConsumer(brokers []string, groupID, topic string) (*KafkaConsumer, error) {
-	config := sarama.NewConfig()
+// NewKafkaConsumer handles up to workers messages of each claimed partition at
+// once. Messages with the same key, i.e. of the same account, are always
+// handled one at a time in partition order.
+func NewKafkaConsumer(brokers []string, groupID, topic string, opts KafkaOptions, retry RetryPolicy, workers int) (*KafkaConsumer, error) {
+	config, err := opts.config()
+	if err != nil {
+		return nil, err
+	}
 	config.Consumer.Group.Rebalance.Strategy = sarama.NewBalanceStrategyRoundRobin()
-	config.Consumer.Offsets.Initial = sarama.OffsetNewest
+	config.Producer.Return.Successes = true
+
+	if retry.DeadLetterTopic == "" {
+		retry.DeadLetterTopic = topic + ".dlq"
+	}
+	if workers < 1 {
+		workers = 1
+	}
 
 	group, err := sarama.NewConsumerGroup(brokers, groupID, config)
 	if err != nil {
 		return nil, err
 	}
 
+	dlq, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		group.Close()
+		return nil, err
+	}
+
 	return &KafkaConsumer{
-		group: group,
-		topic: topic,
+		group:   group,
+		dlq:     dlq,
+		topic:   topic,
+		retry:   retry,
+		workers: workers,
 	}, nil
 }
 
@@ -86,24 +121,73 @@ func (c *KafkaConsumer) Consume(ctx context.Context, handler func(msg domain.Mes
 }
 
 func (c *KafkaConsumer) Close() error {
-	return c.group.Close()
+	if err := c.group.Close(); err != nil {
+		c.dlq.Close()
+		return err
+	}
+	return c.dlq.Close()
 }
 
 func (c *KafkaConsumer) Setup(_ sarama.ConsumerGroupSession) error   { return nil }
 func (c *KafkaConsumer) Cleanup(_ sarama.ConsumerGroupSession) error { return nil }
 
+// ConsumeClaim marks a message only once it was handled or dead-lettered.
+// If neither happened (shutdown, DLQ unavailable) the claim stops so the
+// message is redelivered instead of being skipped by a later mark.
 func (c *KafkaConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	if c.workers > 1 {
+		return c.consumeConcurrently(session, claim)
+	}
+
 	for msg := range claim.Messages() {
-		var message domain.Message
-		if err := json.Unmarshal(msg.Value, &message); err != nil {
-			continue
+		if err := c.process(session.Context(), msg); err != nil {
+			return err
 		}
+		session.MarkMessage(msg, "")
+	}
+	return nil
+}
 
-		if err := c.handler(message); err != nil {
-			continue
-		}
+func (c *KafkaConsumer) process(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	env, err := decodeEnvelope(msg.Headers, msg.Value)
+	if err != nil {
+		// Undecodable payloads will never succeed, don't retry them
+		return c.deadLetter(msg, err, 1)
+	}
 
-		session.MarkMessage(msg, "")
+	id := fmt.Sprintf("partition=%d offset=%d trace=%s", msg.Partition, msg.Offset, env.TraceID)
+	attempts, err := c.retry.run(ctx, id, func() error { return c.handler(env.Message) })
+	if err == nil || ctx.Err() != nil {
+		return err
 	}
+
+	return c.deadLetter(msg, err, attempts)
+}
+
+func (c *KafkaConsumer) deadLetter(msg *sarama.ConsumerMessage, cause error, attempts int) error {
+	// Keep the envelope headers so a replay decodes the payload the same way
+	headers := envelopeHeaders(msg.Headers)
+	headers = append(headers,
+		sarama.RecordHeader{Key: []byte(headerError), Value: []byte(cause.Error())},
+		sarama.RecordHeader{Key: []byte(headerAttempts), Value: []byte(strconv.Itoa(attempts))},
+		sarama.RecordHeader{Key: []byte(headerTopic), Value: []byte(msg.Topic)},
+		sarama.RecordHeader{Key: []byte(headerPartition), Value: []byte(strconv.Itoa(int(msg.Partition)))},
+		sarama.RecordHeader{Key: []byte(headerOffset), Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		sarama.RecordHeader{Key: []byte(headerFailedAt), Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+	)
+
+	_, _, err := c.dlq.SendMessage(&sarama.ProducerMessage{
+		Topic:   c.retry.DeadLetterTopic,
+		Key:     sarama.ByteEncoder(msg.Key),
+		Value:   sarama.ByteEncoder(msg.Value),
+		Headers: headers,
+	})
+	if err != nil {
+		log.Printf("[DLQ ERROR] partition=%d offset=%d: %v", msg.Partition, msg.Offset, err)
+		return err
+	}
+
+	log.Printf("[DLQ] partition=%d offset=%d moved to %s after %d attempts: %v",
+		msg.Partition, msg.Offset, c.retry.DeadLetterTopic, attempts, cause)
 	return nil
 }
@@ -0,0 +1,216 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"tokenlaunch/internal/domain"
+)
+
+// Delivery is the outcome of a message a publisher accepted. Err is nil once
+// the broker acknowledged it.
+type Delivery struct {
+	Message domain.Message
+	// Account is the tag set with WithAccount when the message was published.
+	Account string
+	Err     error
+}
+
+// DeliveryReporter is implemented by publishers whose Publish returns before
+// the message is delivered. Outcomes are reported on Deliveries, which must be
+// read until it is closed or failed messages back up the publisher.
+type DeliveryReporter interface {
+	Deliveries() <-chan Delivery
+}
+
+type accountKey struct{}
+
+// WithAccount tags messages published with ctx so a Delivery can be
+// traced back to the account that was scraped.
+func WithAccount(ctx context.Context, account string) context.Context {
+	return context.WithValue(ctx, accountKey{}, account)
+}
+
+type AsyncOptions struct {
+	// Compression is none, gzip, snappy, lz4 or zstd.
+	Compression string
+	// BatchSize is how many buffered messages trigger a send without waiting
+	// for Linger, and the most sent per request.
+	BatchSize int
+	// Linger is how long a batch may wait to fill up.
+	Linger time.Duration
+}
+
+// KafkaAsync is a batching, idempotent producer. Publish only enqueues; the
+// outcome of each message is reported on Deliveries.
+type KafkaAsync struct {
+	producer   sarama.AsyncProducer
+	topic      string
+	producerID string
+	deliveries chan Delivery
+	// done is closed once every outcome was reported.
+	done chan struct{}
+
+	// mu keeps Publish from sending on the input channel while Close shuts
+	// the producer down.
+	mu     sync.RWMutex
+	closed bool
+}
+
+type asyncMetadata struct {
+	msg     domain.Message
+	account string
+}
+
+func NewKafkaAsync(brokers []string, topic string, kafka KafkaOptions, opts AsyncOptions) (*KafkaAsync, error) {
+	codec, err := compressionCodec(opts.Compression)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := kafka.config()
+	if err != nil {
+		return nil, err
+	}
+	config.Producer.Compression = codec
+	config.Producer.Flush.Messages = opts.BatchSize
+	config.Producer.Flush.MaxMessages = opts.BatchSize
+	config.Producer.Flush.Frequency = opts.Linger
+	config.Producer.Return.Errors = true
+	config.Producer.Return.Successes = true
+
+	// Idempotence keeps broker-side retries from duplicating or reordering.
+	// It requires acks from all in-sync replicas whatever RequiredAcks says.
+	config.Producer.Idempotent = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Retry.Max = 10
+	config.Net.MaxOpenRequests = 1
+
+	producer, err := sarama.NewAsyncProducer(brokers, config)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &KafkaAsync{
+		producer:   producer,
+		topic:      topic,
+		producerID: defaultProducerID(),
+		deliveries: make(chan Delivery, 1024),
+		done:       make(chan struct{}),
+	}
+	go k.report()
+
+	return k, nil
+}
+
+func (k *KafkaAsync) Publish(ctx context.Context, msg domain.Message) error {
+	headers, data, err := encodeEnvelope(ctx, k.producerID, msg)
+	if err != nil {
+		return err
+	}
+
+	account, _ := ctx.Value(accountKey{}).(string)
+
+	pm := &sarama.ProducerMessage{
+		Topic:    k.topic,
+		Key:      sarama.StringEncoder(string(msg.Source) + ":" + msg.Username),
+		Value:    sarama.ByteEncoder(data),
+		Headers:  headers,
+		Metadata: asyncMetadata{msg: msg, account: account},
+	}
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.closed {
+		return ErrClosed
+	}
+
+	select {
+	case k.producer.Input() <- pm:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (k *KafkaAsync) Deliveries() <-chan Delivery {
+	return k.deliveries
+}
+
+// report forwards the producer's successes and errors to Deliveries until
+// the producer shuts down.
+func (k *KafkaAsync) report() {
+	defer close(k.done)
+	defer close(k.deliveries)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for pm := range k.producer.Successes() {
+			k.deliver(pm, nil)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for perr := range k.producer.Errors() {
+			k.deliver(perr.Msg, perr.Err)
+		}
+	}()
+	wg.Wait()
+}
+
+// deliver reports one outcome. A failure is already marked seen and behind
+// the account's cursor, so its report is the only way back into a retry and
+// waits for the reader; a success report only feeds the counters and is
+// dropped rather than stall the producer.
+func (k *KafkaAsync) deliver(pm *sarama.ProducerMessage, err error) {
+	meta, _ := pm.Metadata.(asyncMetadata)
+	d := Delivery{Message: meta.msg, Account: meta.account, Err: err}
+
+	if err != nil {
+		k.deliveries <- d
+		return
+	}
+
+	select {
+	case k.deliveries <- d:
+	default:
+	}
+}
+
+// Close stops accepting messages and waits until the buffered ones are
+// delivered or failed and every outcome was sent to Deliveries, which is
+// closed afterwards.
+func (k *KafkaAsync) Close() error {
+	k.mu.Lock()
+	if !k.closed {
+		k.closed = true
+		k.producer.AsyncClose()
+	}
+	k.mu.Unlock()
+
+	<-k.done
+	return nil
+}
+
+func compressionCodec(name string) (sarama.CompressionCodec, error) {
+	switch name {
+	case "", "none":
+		return sarama.CompressionNone, nil
+	case "gzip":
+		return sarama.CompressionGZIP, nil
+	case "snappy":
+		return sarama.CompressionSnappy, nil
+	case "lz4":
+		return sarama.CompressionLZ4, nil
+	case "zstd":
+		return sarama.CompressionZSTD, nil
+	default:
+		return sarama.CompressionNone, fmt.Errorf("unknown compression %q", name)
+	}
+}
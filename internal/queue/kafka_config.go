@@ -0,0 +1,185 @@
+package queue
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/xdg-go/scram"
+)
+
+// KafkaOptions are the connection and tuning settings shared by every Kafka
+// client. Zero values keep sarama's defaults.
+type KafkaOptions struct {
+	ClientID string
+	// Version is the broker protocol version, e.g. "3.6.0".
+	Version string
+
+	// SASLMechanism is PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512; empty disables SASL.
+	SASLMechanism string
+	SASLUsername  string
+	SASLPassword  string
+
+	TLSEnabled bool
+	// TLSCAFile verifies the brokers against a private CA instead of the
+	// system roots. TLSCertFile and TLSKeyFile enable client authentication.
+	TLSCAFile             string
+	TLSCertFile           string
+	TLSKeyFile            string
+	TLSInsecureSkipVerify bool
+
+	// RequiredAcks is all, leader or none.
+	RequiredAcks string
+	// InitialOffset is where a new consumer group starts: newest or oldest.
+	InitialOffset     string
+	SessionTimeout    time.Duration
+	HeartbeatInterval time.Duration
+	RebalanceTimeout  time.Duration
+}
+
+// config builds the sarama configuration every constructor starts from.
+func (o KafkaOptions) config() (*sarama.Config, error) {
+	config := sarama.NewConfig()
+
+	if o.ClientID != "" {
+		config.ClientID = o.ClientID
+	}
+	if o.Version != "" {
+		v, err := sarama.ParseKafkaVersion(o.Version)
+		if err != nil {
+			return nil, err
+		}
+		config.Version = v
+	}
+
+	if err := o.applySASL(config); err != nil {
+		return nil, err
+	}
+	if err := o.applyTLS(config); err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(o.RequiredAcks) {
+	case "":
+	case "all":
+		config.Producer.RequiredAcks = sarama.WaitForAll
+	case "leader":
+		config.Producer.RequiredAcks = sarama.WaitForLocal
+	case "none":
+		config.Producer.RequiredAcks = sarama.NoResponse
+	default:
+		return nil, fmt.Errorf("unknown required acks %q", o.RequiredAcks)
+	}
+
+	config.Consumer.Offsets.Initial = sarama.OffsetNewest
+	switch strings.ToLower(o.InitialOffset) {
+	case "", "newest":
+	case "oldest":
+		config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	default:
+		return nil, fmt.Errorf("unknown initial offset %q", o.InitialOffset)
+	}
+
+	if o.SessionTimeout > 0 {
+		config.Consumer.Group.Session.Timeout = o.SessionTimeout
+	}
+	if o.HeartbeatInterval > 0 {
+		config.Consumer.Group.Heartbeat.Interval = o.HeartbeatInterval
+	}
+	if o.RebalanceTimeout > 0 {
+		config.Consumer.Group.Rebalance.Timeout = o.RebalanceTimeout
+	}
+
+	return config, nil
+}
+
+func (o KafkaOptions) applySASL(config *sarama.Config) error {
+	if o.SASLMechanism == "" {
+		return nil
+	}
+
+	config.Net.SASL.Enable = true
+	config.Net.SASL.User = o.SASLUsername
+	config.Net.SASL.Password = o.SASLPassword
+
+	switch strings.ToUpper(o.SASLMechanism) {
+	case "PLAIN":
+		config.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+	case "SCRAM-SHA-256":
+		config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
+		config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+			return &scramClient{hash: scram.SHA256}
+		}
+	case "SCRAM-SHA-512":
+		config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
+		config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+			return &scramClient{hash: scram.SHA512}
+		}
+	default:
+		return fmt.Errorf("unknown SASL mechanism %q", o.SASLMechanism)
+	}
+	return nil
+}
+
+func (o KafkaOptions) applyTLS(config *sarama.Config) error {
+	if !o.TLSEnabled {
+		return nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: o.TLSInsecureSkipVerify,
+	}
+
+	if o.TLSCAFile != "" {
+		pem, err := os.ReadFile(o.TLSCAFile)
+		if err != nil {
+			return fmt.Errorf("read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return errors.New("no certificates found in CA file")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if o.TLSCertFile != "" || o.TLSKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.TLSCertFile, o.TLSKeyFile)
+		if err != nil {
+			return fmt.Errorf("load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	config.Net.TLS.Enable = true
+	config.Net.TLS.Config = tlsConfig
+	return nil
+}
+
+// scramClient adapts xdg-go/scram to sarama's SCRAMClient.
+type scramClient struct {
+	hash scram.HashGeneratorFcn
+	conv *scram.ClientConversation
+}
+
+func (c *scramClient) Begin(user, password, authzID string) error {
+	client, err := c.hash.NewClient(user, password, authzID)
+	if err != nil {
+		return err
+	}
+	c.conv = client.NewConversation()
+	return nil
+}
+
+func (c *scramClient) Step(challenge string) (string, error) {
+	return c.conv.Step(challenge)
+}
+
+func (c *scramClient) Done() bool {
+	return c.conv.Done()
+}
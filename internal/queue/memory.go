@@ -0,0 +1,74 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+
+	"tokenlaunch/internal/domain"
+)
+
+var ErrClosed = errors.New("queue closed")
+
+// Memory is an in-process queue that is both the Publisher and the Consumer.
+// Messages are delivered in publish order to a single handler. Nothing is
+// persisted: messages still buffered when the process exits are lost, and a
+// message whose handler still fails after the retries is logged and dropped,
+// as there is no dead-letter topic.
+type Memory struct {
+	msgs      chan domain.Message
+	done      chan struct{}
+	closeOnce sync.Once
+	retry     RetryPolicy
+}
+
+// NewMemory buffers up to size messages; Publish blocks while it is full.
+// The DeadLetterTopic of retry is ignored.
+func NewMemory(size int, retry RetryPolicy) *Memory {
+	return &Memory{
+		msgs:  make(chan domain.Message, size),
+		done:  make(chan struct{}),
+		retry: retry,
+	}
+}
+
+func (m *Memory) Publish(ctx context.Context, msg domain.Message) error {
+	select {
+	case <-m.done:
+		return ErrClosed
+	default:
+	}
+
+	select {
+	case m.msgs <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-m.done:
+		return ErrClosed
+	}
+}
+
+func (m *Memory) Consume(ctx context.Context, handler func(msg domain.Message) error) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-m.done:
+			return nil
+		case msg := <-m.msgs:
+			attempts, err := m.retry.run(ctx, msg.ID, func() error { return handler(msg) })
+			if err != nil && ctx.Err() == nil {
+				log.Printf("[DROPPED] message id=%s after %d attempts: %v", msg.ID, attempts, err)
+			}
+		}
+	}
+}
+
+// Close stops Consume and rejects further publishes. It is safe to call
+// from both the publishing and the consuming side.
+func (m *Memory) Close() error {
+	m.closeOnce.Do(func() { close(m.done) })
+	return nil
+}
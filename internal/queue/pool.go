@@ -0,0 +1,131 @@
+package queue
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+
+	"github.com/IBM/sarama"
+)
+
+type claimResult struct {
+	msg *sarama.ConsumerMessage
+	err error
+}
+
+// consumeConcurrently spreads a claim over c.workers goroutines, sharded by
+// message key so each account's messages stay in order. Offsets are marked
+// only up to the oldest message still in flight, so work that hasn't
+// finished is redelivered after a crash or rebalance rather than skipped.
+func (c *KafkaConsumer) consumeConcurrently(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	ctx, cancel := context.WithCancel(session.Context())
+	defer cancel()
+
+	results := make(chan claimResult)
+	shards := make([]chan *sarama.ConsumerMessage, c.workers)
+
+	var wg sync.WaitGroup
+	for i := range shards {
+		shards[i] = make(chan *sarama.ConsumerMessage, 1)
+		wg.Add(1)
+		go func(in <-chan *sarama.ConsumerMessage) {
+			defer wg.Done()
+			for msg := range in {
+				// Don't start new work once the claim is failing or ending
+				if err := ctx.Err(); err != nil {
+					results <- claimResult{msg: msg, err: err}
+					continue
+				}
+				results <- claimResult{msg: msg, err: c.process(ctx, msg)}
+			}
+		}(shards[i])
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var (
+		inflight = newInflight()
+		failed   error
+	)
+	complete := func(r claimResult) {
+		if r.err != nil {
+			if failed == nil {
+				failed = r.err
+				cancel()
+			}
+			return
+		}
+		for _, msg := range inflight.done(r.msg) {
+			session.MarkMessage(msg, "")
+		}
+	}
+
+dispatch:
+	for failed == nil {
+		select {
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				break dispatch
+			}
+			inflight.add(msg)
+			shard := shards[shardFor(msg.Key, len(shards))]
+			for sent := false; !sent && failed == nil; {
+				select {
+				case shard <- msg:
+					sent = true
+				case r := <-results:
+					complete(r)
+				}
+			}
+		case r := <-results:
+			complete(r)
+		}
+	}
+
+	for _, shard := range shards {
+		close(shard)
+	}
+	for r := range results {
+		complete(r)
+	}
+
+	return failed
+}
+
+func shardFor(key []byte, n int) int {
+	h := fnv.New32a()
+	h.Write(key)
+	return int(h.Sum32() % uint32(n))
+}
+
+// inflight tracks dispatched messages of one partition in offset order.
+type inflight struct {
+	msgs     []*sarama.ConsumerMessage
+	finished map[int64]bool
+}
+
+func newInflight() *inflight {
+	return &inflight{finished: make(map[int64]bool)}
+}
+
+func (f *inflight) add(msg *sarama.ConsumerMessage) {
+	f.msgs = append(f.msgs, msg)
+}
+
+// done records msg as finished and returns the messages that can now be
+// marked: the finished prefix of the in-flight list.
+func (f *inflight) done(msg *sarama.ConsumerMessage) []*sarama.ConsumerMessage {
+	f.finished[msg.Offset] = true
+
+	n := 0
+	for n < len(f.msgs) && f.finished[f.msgs[n].Offset] {
+		delete(f.finished, f.msgs[n].Offset)
+		n++
+	}
+
+	ready := f.msgs[:n]
+	f.msgs = f.msgs[n:]
+	return ready
+}
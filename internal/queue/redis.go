@@ -0,0 +1,258 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"tokenlaunch/internal/domain"
+)
+
+const (
+	// streamBlock is how long XREADGROUP waits for new entries, which bounds
+	// how quickly the consumer notices shutdown and stuck entries.
+	streamBlock = 5 * time.Second
+	streamBatch = 10
+	// claimIdle is how long an entry may stay unacknowledged before another
+	// consumer takes it over, e.g. after the owner crashed.
+	claimIdle     = 5 * time.Minute
+	claimInterval = 30 * time.Second
+)
+
+// RedisStream publishes messages to a Redis stream.
+type RedisStream struct {
+	rdb    *redis.Client
+	stream string
+	maxLen int64
+}
+
+// NewRedisStream publishes to stream, trimming it to roughly maxLen entries.
+// A maxLen of 0 keeps every entry.
+func NewRedisStream(addr, stream string, maxLen int64) (*RedisStream, error) {
+	rdb, err := connectRedis(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RedisStream{
+		rdb:    rdb,
+		stream: stream,
+		maxLen: maxLen,
+	}, nil
+}
+
+func (r *RedisStream) Publish(ctx context.Context, msg domain.Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return r.rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: r.stream,
+		MaxLen: r.maxLen,
+		Approx: r.maxLen > 0,
+		Values: map[string]any{
+			"key":  string(msg.Source) + ":" + msg.Username,
+			"data": data,
+		},
+	}).Err()
+}
+
+func (r *RedisStream) Close() error {
+	return r.rdb.Close()
+}
+
+// RedisStreamConsumer reads a Redis stream as part of a consumer group. An
+// entry is acknowledged once it was handled or dead-lettered; entries left
+// pending by a consumer that went away are reclaimed after claimIdle.
+type RedisStreamConsumer struct {
+	rdb     *redis.Client
+	stream  string
+	group   string
+	name    string
+	retry   RetryPolicy
+	handler func(msg domain.Message) error
+}
+
+func NewRedisStreamConsumer(addr, groupID, stream string, retry RetryPolicy) (*RedisStreamConsumer, error) {
+	rdb, err := connectRedis(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if retry.DeadLetterTopic == "" {
+		retry.DeadLetterTopic = stream + ".dlq"
+	}
+
+	// Stable across restarts so a restarted consumer picks up its own entries
+	name, _ := os.Hostname()
+	if name == "" {
+		name = "consumer-" + strconv.Itoa(os.Getpid())
+	}
+
+	return &RedisStreamConsumer{
+		rdb:    rdb,
+		stream: stream,
+		group:  groupID,
+		name:   name,
+		retry:  retry,
+	}, nil
+}
+
+func (c *RedisStreamConsumer) Consume(ctx context.Context, handler func(msg domain.Message) error) error {
+	c.handler = handler
+
+	// Like Kafka's OffsetNewest, a new group starts at the end of the stream
+	err := c.rdb.XGroupCreateMkStream(ctx, c.stream, c.group, "$").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+
+	var lastClaim time.Time
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if time.Since(lastClaim) >= claimInterval {
+			if err := c.claimStuck(ctx); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return err
+			}
+			lastClaim = time.Now()
+		}
+
+		streams, err := c.rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    c.group,
+			Consumer: c.name,
+			Streams:  []string{c.stream, ">"},
+			Count:    streamBatch,
+			Block:    streamBlock,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		for _, s := range streams {
+			for _, msg := range s.Messages {
+				c.process(ctx, msg)
+			}
+		}
+	}
+}
+
+// claimStuck takes over entries that stayed pending longer than claimIdle,
+// including this consumer's own from before a restart.
+func (c *RedisStreamConsumer) claimStuck(ctx context.Context) error {
+	start := "0-0"
+	for {
+		msgs, next, err := c.rdb.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   c.stream,
+			Group:    c.group,
+			Consumer: c.name,
+			MinIdle:  claimIdle,
+			Start:    start,
+			Count:    streamBatch,
+		}).Result()
+		if err != nil {
+			return err
+		}
+
+		if len(msgs) > 0 {
+			log.Printf("[CLAIM] took over %d stuck entries of %s", len(msgs), c.stream)
+		}
+		for _, msg := range msgs {
+			c.process(ctx, msg)
+		}
+
+		if next == "0-0" || ctx.Err() != nil {
+			return nil
+		}
+		start = next
+	}
+}
+
+// process leaves the entry pending when it can neither be handled nor
+// dead-lettered, so it is retried once reclaimed.
+func (c *RedisStreamConsumer) process(ctx context.Context, msg redis.XMessage) {
+	data, _ := msg.Values["data"].(string)
+
+	var message domain.Message
+	if err := json.Unmarshal([]byte(data), &message); err != nil {
+		// Undecodable payloads will never succeed, don't retry them
+		c.deadLetter(ctx, msg, err, 1)
+		return
+	}
+
+	attempts, err := c.retry.run(ctx, "entry="+msg.ID, func() error { return c.handler(message) })
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		c.deadLetter(ctx, msg, err, attempts)
+		return
+	}
+
+	if err := c.rdb.XAck(ctx, c.stream, c.group, msg.ID).Err(); err != nil {
+		log.Printf("[ACK ERROR] entry=%s: %v", msg.ID, err)
+	}
+}
+
+func (c *RedisStreamConsumer) deadLetter(ctx context.Context, msg redis.XMessage, cause error, attempts int) {
+	pipe := c.rdb.TxPipeline()
+	pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: c.retry.DeadLetterTopic,
+		Values: map[string]any{
+			"key":          msg.Values["key"],
+			"data":         msg.Values["data"],
+			headerError:    cause.Error(),
+			headerAttempts: attempts,
+			headerTopic:    c.stream,
+			headerOffset:   msg.ID,
+			headerFailedAt: time.Now().UTC().Format(time.RFC3339),
+		},
+	})
+	pipe.XAck(ctx, c.stream, c.group, msg.ID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("[DLQ ERROR] entry=%s: %v", msg.ID, err)
+		return
+	}
+
+	log.Printf("[DLQ] entry=%s moved to %s after %d attempts: %v", msg.ID, c.retry.DeadLetterTopic, attempts, cause)
+}
+
+func (c *RedisStreamConsumer) Close() error {
+	return c.rdb.Close()
+}
+
+func connectRedis(addr string) (*redis.Client, error) {
+	rdb := redis.NewClient(&redis.Options{
+		Addr: addr,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		rdb.Close()
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+
+	return rdb, nil
+}
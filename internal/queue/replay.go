@@ -0,0 +1,143 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"tokenlaunch/internal/domain"
+)
+
+// KafkaReplay is a Consumer that reads the messages produced between two
+// points in time from every partition of a topic and then stops. It doesn't
+// join a consumer group or commit offsets, so the live consumer is unaffected.
+type KafkaReplay struct {
+	client sarama.Client
+	topic  string
+	from   time.Time
+	to     time.Time
+}
+
+func NewKafkaReplay(brokers []string, topic string, opts KafkaOptions, from, to time.Time) (*KafkaReplay, error) {
+	if !to.After(from) {
+		return nil, errors.New("replay range is empty")
+	}
+
+	config, err := opts.config()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := sarama.NewClient(brokers, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KafkaReplay{
+		client: client,
+		topic:  topic,
+		from:   from,
+		to:     to,
+	}, nil
+}
+
+// Consume runs handler for every message in range, partitions in parallel and
+// each partition in order. Handler errors are logged and skipped.
+func (r *KafkaReplay) Consume(ctx context.Context, handler func(msg domain.Message) error) error {
+	partitions, err := r.client.Partitions(r.topic)
+	if err != nil {
+		return err
+	}
+
+	consumer, err := sarama.NewConsumerFromClient(r.client)
+	if err != nil {
+		return err
+	}
+	defer consumer.Close()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for _, partition := range partitions {
+		wg.Add(1)
+		go func(partition int32) {
+			defer wg.Done()
+			if err := r.replayPartition(ctx, consumer, partition, handler); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}(partition)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return nil
+	}
+	return firstErr
+}
+
+func (r *KafkaReplay) replayPartition(ctx context.Context, consumer sarama.Consumer, partition int32, handler func(msg domain.Message) error) error {
+	// GetOffset with a timestamp returns the first offset produced at or
+	// after it, or -1 when there is none
+	start, err := r.client.GetOffset(r.topic, partition, r.from.UnixMilli())
+	if err != nil {
+		return err
+	}
+	if start == -1 {
+		return nil
+	}
+
+	end, err := r.client.GetOffset(r.topic, partition, r.to.UnixMilli())
+	if err != nil {
+		return err
+	}
+	if end == -1 {
+		if end, err = r.client.GetOffset(r.topic, partition, sarama.OffsetNewest); err != nil {
+			return err
+		}
+	}
+	if start >= end {
+		return nil
+	}
+
+	log.Printf("[REPLAY] partition %d: offsets %d-%d", partition, start, end-1)
+
+	pc, err := consumer.ConsumePartition(r.topic, partition, start)
+	if err != nil {
+		return err
+	}
+	defer pc.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-pc.Errors():
+			return err
+		case msg := <-pc.Messages():
+			env, err := decodeEnvelope(msg.Headers, msg.Value)
+			if err != nil {
+				log.Printf("[REPLAY ERROR] partition=%d offset=%d: %v", partition, msg.Offset, err)
+			} else if err := handler(env.Message); err != nil {
+				log.Printf("[REPLAY ERROR] partition=%d offset=%d: %v", partition, msg.Offset, err)
+			}
+
+			if msg.Offset+1 >= end {
+				return nil
+			}
+		}
+	}
+}
+
+func (r *KafkaReplay) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,37 @@
+package queue
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RetryPolicy controls how often a failing message is retried before it is
+// moved to the dead-letter topic.
+type RetryPolicy struct {
+	MaxRetries      int
+	Backoff         time.Duration
+	DeadLetterTopic string
+}
+
+// run calls fn until it succeeds or MaxRetries retries failed, doubling the
+// wait between attempts. It returns the last error and the attempts made, or
+// ctx.Err() if the context ends while waiting.
+func (p RetryPolicy) run(ctx context.Context, id string, fn func() error) (int, error) {
+	var err error
+	for attempt := 0; attempt <= p.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return attempt, ctx.Err()
+			case <-time.After(p.Backoff << (attempt - 1)):
+			}
+		}
+
+		if err = fn(); err == nil {
+			return attempt + 1, nil
+		}
+		log.Printf("[RETRY] %s attempt=%d: %v", id, attempt+1, err)
+	}
+	return p.MaxRetries + 1, err
+}
@@ -2,9 +2,12 @@ package redis
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+
+	"tokenlaunch/internal/domain"
 )
 
 type Client struct {
@@ -31,20 +34,170 @@ func (c *Client) Close() error {
 }
 
 // Account management
-func (c *Client) AddAccount(ctx context.Context, username string) error {
-	return c.rdb.SAdd(ctx, "accounts", username).Err()
+func accountsKey(source domain.Source) string {
+	if source == domain.SourceTwitter {
+		return "accounts"
+	}
+	return "accounts:" + string(source)
+}
+
+func (c *Client) AddAccount(ctx context.Context, source domain.Source, username string) error {
+	return c.rdb.SAdd(ctx, accountsKey(source), username).Err()
+}
+
+func (c *Client) RemoveAccount(ctx context.Context, source domain.Source, username string) error {
+	pipe := c.rdb.TxPipeline()
+	pipe.SRem(ctx, accountsKey(source), username)
+	pipe.HDel(ctx, settingsKey(source), username)
+	pipe.HDel(ctx, statusKey(source), username)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (c *Client) GetAccounts(ctx context.Context, source domain.Source) ([]string, error) {
+	return c.rdb.SMembers(ctx, accountsKey(source)).Result()
 }
 
-func (c *Client) RemoveAccount(ctx context.Context, username string) error {
-	return c.rdb.SRem(ctx, "accounts", username).Err()
+func (c *Client) AccountExists(ctx context.Context, source domain.Source, username string) (bool, error) {
+	return c.rdb.SIsMember(ctx, accountsKey(source), username).Result()
 }
 
-func (c *Client) GetAccounts(ctx context.Context) ([]string, error) {
-	return c.rdb.SMembers(ctx, "accounts").Result()
+// Account settings
+func settingsKey(source domain.Source) string {
+	return "account_settings:" + string(source)
 }
 
-func (c *Client) AccountExists(ctx context.Context, username string) (bool, error) {
-	return c.rdb.SIsMember(ctx, "accounts", username).Result()
+func (c *Client) SetAccountSettings(ctx context.Context, source domain.Source, username string, settings domain.AccountSettings) error {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	return c.rdb.HSet(ctx, settingsKey(source), username, data).Err()
+}
+
+// GetAllAccountSettings returns settings keyed by username. Accounts without
+// stored settings are absent from the map.
+func (c *Client) GetAllAccountSettings(ctx context.Context, source domain.Source) (map[string]domain.AccountSettings, error) {
+	raw, err := c.rdb.HGetAll(ctx, settingsKey(source)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	all := make(map[string]domain.AccountSettings, len(raw))
+	for username, data := range raw {
+		var settings domain.AccountSettings
+		if err := json.Unmarshal([]byte(data), &settings); err != nil {
+			return nil, err
+		}
+		all[username] = settings
+	}
+	return all, nil
+}
+
+// Account status
+func statusKey(source domain.Source) string {
+	return "account_status:" + string(source)
+}
+
+func (c *Client) SetAccountStatus(ctx context.Context, source domain.Source, username string, status domain.AccountStatus) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	return c.rdb.HSet(ctx, statusKey(source), username, data).Err()
+}
+
+func (c *Client) ClearAccountStatus(ctx context.Context, source domain.Source, username string) error {
+	return c.rdb.HDel(ctx, statusKey(source), username).Err()
+}
+
+// GetAllAccountStatus returns statuses keyed by username. Accounts that have
+// never failed are absent from the map.
+func (c *Client) GetAllAccountStatus(ctx context.Context, source domain.Source) (map[string]domain.AccountStatus, error) {
+	raw, err := c.rdb.HGetAll(ctx, statusKey(source)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	all := make(map[string]domain.AccountStatus, len(raw))
+	for username, data := range raw {
+		var status domain.AccountStatus
+		if err := json.Unmarshal([]byte(data), &status); err != nil {
+			return nil, err
+		}
+		all[username] = status
+	}
+	return all, nil
+}
+
+// Message dedup
+
+// MarkSeen records a message ID for ttl and reports whether it was new.
+// SET NX makes this safe to call from several scraper replicas at once.
+func (c *Client) MarkSeen(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	return c.rdb.SetNX(ctx, "seen:"+id, 1, ttl).Result()
+}
+
+func (c *Client) UnmarkSeen(ctx context.Context, id string) error {
+	return c.rdb.Del(ctx, "seen:"+id).Err()
+}
+
+// Polling cursors
+func cursorKey(source domain.Source, account string) string {
+	return "cursor:" + string(source) + ":" + account
+}
+
+func (c *Client) GetCursor(ctx context.Context, source domain.Source, account string) (*domain.Cursor, error) {
+	data, err := c.rdb.Get(ctx, cursorKey(source, account)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor domain.Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+func (c *Client) SetCursor(ctx context.Context, source domain.Source, account string, cursor domain.Cursor) error {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return err
+	}
+	return c.rdb.Set(ctx, cursorKey(source, account), data, 0).Err()
+}
+
+func (c *Client) DeleteCursor(ctx context.Context, source domain.Source, account string) error {
+	return c.rdb.Del(ctx, cursorKey(source, account)).Err()
+}
+
+// Scraper instance health
+func (c *Client) SetInstanceHealth(ctx context.Context, source domain.Source, health []domain.InstanceHealth) error {
+	data, err := json.Marshal(health)
+	if err != nil {
+		return err
+	}
+	return c.rdb.Set(ctx, "health:"+string(source), data, 10*time.Minute).Err()
+}
+
+func (c *Client) GetInstanceHealth(ctx context.Context, source domain.Source) ([]domain.InstanceHealth, error) {
+	data, err := c.rdb.Get(ctx, "health:"+string(source)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var health []domain.InstanceHealth
+	if err := json.Unmarshal(data, &health); err != nil {
+		return nil, err
+	}
+	return health, nil
 }
 
 // Task queue
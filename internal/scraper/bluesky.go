@@ -0,0 +1,211 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"tokenlaunch/internal/domain"
+)
+
+const blueskyAPI = "https://public.api.bsky.app"
+
+type Bluesky struct {
+	baseURL string
+	client  *http.Client
+}
+
+type bskyAuthorFeed struct {
+	Feed []struct {
+		Post   bskyPost `json:"post"`
+		Reason *struct {
+			Type string `json:"$type"`
+		} `json:"reason"`
+		Reply *struct {
+			Parent struct {
+				Author bskyAuthor `json:"author"`
+			} `json:"parent"`
+		} `json:"reply"`
+	} `json:"feed"`
+}
+
+type bskyAuthor struct {
+	DID         string `json:"did"`
+	Handle      string `json:"handle"`
+	DisplayName string `json:"displayName"`
+}
+
+type bskyPost struct {
+	URI    string     `json:"uri"`
+	Author bskyAuthor `json:"author"`
+	Record struct {
+		Text      string    `json:"text"`
+		CreatedAt time.Time `json:"createdAt"`
+		Reply     *struct{} `json:"reply"`
+		Facets    []struct {
+			Features []struct {
+				Type string `json:"$type"`
+				URI  string `json:"uri"`
+			} `json:"features"`
+		} `json:"facets"`
+	} `json:"record"`
+	Embed *bskyEmbed `json:"embed"`
+}
+
+// bskyEmbed covers the image, external link, quote and quote-with-media views.
+type bskyEmbed struct {
+	Type   string `json:"$type"`
+	Images []struct {
+		Fullsize string `json:"fullsize"`
+	} `json:"images"`
+	External *struct {
+		URI string `json:"uri"`
+	} `json:"external"`
+	Record *struct {
+		URI    string `json:"uri"`
+		Record *struct {
+			URI string `json:"uri"`
+		} `json:"record"`
+	} `json:"record"`
+	Media *bskyEmbed `json:"media"`
+}
+
+// NewBluesky polls app.bsky.feed.getAuthorFeed on an XRPC AppView. baseURL
+// defaults to the public AppView and can point at a local mock server.
+func NewBluesky(baseURL string) *Bluesky {
+	if baseURL == "" {
+		baseURL = blueskyAPI
+	}
+
+	return &Bluesky{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (b *Bluesky) Source() domain.Source {
+	return domain.SourceBluesky
+}
+
+// Scrape fetches the latest posts and reposts of a handle.
+func (b *Bluesky) Scrape(ctx context.Context, account string) ([]domain.Message, error) {
+	query := url.Values{}
+	query.Set("actor", account)
+	query.Set("limit", "50")
+	endpoint := fmt.Sprintf("%s/xrpc/app.bsky.feed.getAuthorFeed?%s", b.baseURL, query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusBadRequest, http.StatusNotFound:
+		// XRPC reports unknown actors as 400 InvalidRequest
+		return nil, fmt.Errorf("HTTP %d: %w", resp.StatusCode, ErrAccountNotFound)
+	default:
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	var feed bskyAuthorFeed
+	if err := json.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		return nil, err
+	}
+
+	messages := make([]domain.Message, 0, len(feed.Feed))
+	for _, item := range feed.Feed {
+		post := item.Post
+		if strings.TrimSpace(post.Record.Text) == "" && post.Embed == nil {
+			continue
+		}
+
+		createdAt := post.Record.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+
+		author := post.Author.DisplayName
+		if author == "" {
+			author = post.Author.Handle
+		}
+
+		msg := domain.Message{
+			ID:         generateID("bluesky:" + post.URI),
+			ExternalID: post.URI,
+			Author:     author,
+			Username:   account,
+			Content:    post.Record.Text,
+			Source:     domain.SourceBluesky,
+			CreatedAt:  createdAt,
+		}
+
+		if item.Reason != nil && strings.HasSuffix(item.Reason.Type, "#reasonRepost") {
+			msg.IsRetweet = true
+			msg.OriginalAuthor = post.Author.Handle
+			// A repost shares the original URI, keep it apart from the original
+			msg.ID = generateID("bluesky:repost:" + account + ":" + post.URI)
+		}
+		if post.Record.Reply != nil {
+			msg.IsReply = true
+			if item.Reply != nil {
+				msg.InReplyTo = item.Reply.Parent.Author.Handle
+			}
+		}
+
+		for _, facet := range post.Record.Facets {
+			for _, feature := range facet.Features {
+				if strings.HasSuffix(feature.Type, "#link") {
+					msg.URLs = appendUnique(msg.URLs, feature.URI)
+				}
+			}
+		}
+		parseBskyEmbed(post.Embed, &msg)
+		ExtractEntities(&msg)
+
+		messages = append(messages, msg)
+	}
+
+	return messages, nil
+}
+
+func parseBskyEmbed(embed *bskyEmbed, msg *domain.Message) {
+	if embed == nil {
+		return
+	}
+
+	for _, img := range embed.Images {
+		msg.Media = appendUnique(msg.Media, img.Fullsize)
+	}
+	if embed.External != nil {
+		msg.URLs = appendUnique(msg.URLs, embed.External.URI)
+	}
+	if embed.Record != nil {
+		uri := embed.Record.URI
+		if embed.Record.Record != nil {
+			uri = embed.Record.Record.URI
+		}
+		msg.QuotedURL = bskyPostURL(uri)
+	}
+	parseBskyEmbed(embed.Media, msg)
+}
+
+// bskyPostURL converts at://<did>/app.bsky.feed.post/<rkey> into a bsky.app link.
+func bskyPostURL(uri string) string {
+	parts := strings.Split(strings.TrimPrefix(uri, "at://"), "/")
+	if len(parts) != 3 || parts[1] != "app.bsky.feed.post" {
+		return uri
+	}
+	return fmt.Sprintf("https://bsky.app/profile/%s/post/%s", parts[0], parts[2])
+}
@@ -0,0 +1,135 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tokenlaunch/internal/domain"
+)
+
+const bskyAuthorFeedJSON = `{
+  "feed": [
+    {
+      "post": {
+        "uri": "at://did:plc:alice/app.bsky.feed.post/3k1",
+        "author": {"did": "did:plc:alice", "handle": "alice.bsky.social", "displayName": "Alice"},
+        "record": {
+          "text": "launching $ABC, details inside",
+          "createdAt": "2025-01-06T12:00:00Z",
+          "facets": [{"features": [{"$type": "app.bsky.richtext.facet#link", "uri": "https://abc.xyz"}]}]
+        },
+        "embed": {
+          "$type": "app.bsky.embed.recordWithMedia#view",
+          "record": {"record": {"uri": "at://did:plc:bob/app.bsky.feed.post/3j9"}},
+          "media": {"$type": "app.bsky.embed.images#view", "images": [{"fullsize": "https://cdn.bsky.app/img/1.jpg"}]}
+        }
+      }
+    },
+    {
+      "post": {
+        "uri": "at://did:plc:bob/app.bsky.feed.post/3j8",
+        "author": {"did": "did:plc:bob", "handle": "bob.bsky.social"},
+        "record": {"text": "gm", "createdAt": "2025-01-05T12:00:00Z"}
+      },
+      "reason": {"$type": "app.bsky.feed.defs#reasonRepost"}
+    },
+    {
+      "post": {
+        "uri": "at://did:plc:alice/app.bsky.feed.post/3k0",
+        "author": {"did": "did:plc:alice", "handle": "alice.bsky.social", "displayName": "Alice"},
+        "record": {"text": "thanks!", "createdAt": "2025-01-04T12:00:00Z", "reply": {}}
+      },
+      "reply": {"parent": {"author": {"did": "did:plc:carol", "handle": "carol.bsky.social"}}}
+    },
+    {
+      "post": {
+        "uri": "at://did:plc:alice/app.bsky.feed.post/3jz",
+        "author": {"did": "did:plc:alice", "handle": "alice.bsky.social"},
+        "record": {"text": "  ", "createdAt": "2025-01-03T12:00:00Z"}
+      }
+    }
+  ]
+}`
+
+func blueskyServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/xrpc/app.bsky.feed.getAuthorFeed" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Query().Get("actor") != "alice.bsky.social" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "InvalidRequest", "message": "Profile not found"}`))
+			return
+		}
+		w.Write([]byte(bskyAuthorFeedJSON))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestBlueskyScrape(t *testing.T) {
+	srv := blueskyServer(t)
+	b := NewBluesky(srv.URL)
+
+	msgs, err := b.Scrape(context.Background(), "alice.bsky.social")
+	if err != nil {
+		t.Fatalf("scrape: %v", err)
+	}
+	if len(msgs) != 3 {
+		t.Fatalf("got %d messages, want 3 (blank post skipped)", len(msgs))
+	}
+
+	post, repost, reply := msgs[0], msgs[1], msgs[2]
+
+	uri := "at://did:plc:alice/app.bsky.feed.post/3k1"
+	if post.ID != domain.NewID("bluesky:"+uri) || post.ExternalID != uri {
+		t.Errorf("ID = %s, ExternalID = %s, want bluesky:%s", post.ID, post.ExternalID, uri)
+	}
+	if post.Source != domain.SourceBluesky || post.Author != "Alice" || post.Username != "alice.bsky.social" {
+		t.Errorf("Source = %s, Author = %q, Username = %q", post.Source, post.Author, post.Username)
+	}
+	if want := time.Date(2025, 1, 6, 12, 0, 0, 0, time.UTC); !post.CreatedAt.Equal(want) {
+		t.Errorf("CreatedAt = %s, want %s", post.CreatedAt, want)
+	}
+	if len(post.URLs) != 1 || post.URLs[0] != "https://abc.xyz" {
+		t.Errorf("URLs = %v", post.URLs)
+	}
+	if len(post.Media) != 1 || post.Media[0] != "https://cdn.bsky.app/img/1.jpg" {
+		t.Errorf("Media = %v", post.Media)
+	}
+	if post.QuotedURL != "https://bsky.app/profile/did:plc:bob/post/3j9" {
+		t.Errorf("QuotedURL = %q", post.QuotedURL)
+	}
+	if post.Type() != domain.PostQuote {
+		t.Errorf("Type = %s, want quote", post.Type())
+	}
+
+	if !repost.IsRetweet || repost.OriginalAuthor != "bob.bsky.social" {
+		t.Errorf("repost: IsRetweet = %v, OriginalAuthor = %q", repost.IsRetweet, repost.OriginalAuthor)
+	}
+	if repost.ID == domain.NewID("bluesky:"+repost.ExternalID) {
+		t.Errorf("repost shares the ID of the original post")
+	}
+
+	if !reply.IsReply || reply.InReplyTo != "carol.bsky.social" {
+		t.Errorf("reply: IsReply = %v, InReplyTo = %q", reply.IsReply, reply.InReplyTo)
+	}
+}
+
+func TestBlueskyUnknownActor(t *testing.T) {
+	srv := blueskyServer(t)
+	b := NewBluesky(srv.URL)
+
+	_, err := b.Scrape(context.Background(), "nobody.bsky.social")
+	if !errors.Is(err, ErrAccountNotFound) {
+		t.Fatalf("err = %v, want ErrAccountNotFound", err)
+	}
+}
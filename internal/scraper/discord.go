@@ -0,0 +1,115 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"tokenlaunch/internal/domain"
+)
+
+const discordAPI = "https://discord.com/api/v10"
+
+type Discord struct {
+	token   string
+	baseURL string
+	client  *http.Client
+}
+
+type discordMessage struct {
+	ID        string    `json:"id"`
+	ChannelID string    `json:"channel_id"`
+	Content   string    `json:"content"`
+	Timestamp time.Time `json:"timestamp"`
+	Author    struct {
+		ID         string `json:"id"`
+		Username   string `json:"username"`
+		GlobalName string `json:"global_name"`
+	} `json:"author"`
+}
+
+// NewDiscord reads channel history with a bot token. baseURL defaults to
+// the public Discord API and can point at a local fake server.
+func NewDiscord(token, baseURL string) *Discord {
+	if baseURL == "" {
+		baseURL = discordAPI
+	}
+
+	return &Discord{
+		token:   token,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (d *Discord) Source() domain.Source {
+	return domain.SourceDiscord
+}
+
+// Scrape fetches the latest messages of a channel. account is the channel ID.
+func (d *Discord) Scrape(ctx context.Context, account string) ([]domain.Message, error) {
+	url := fmt.Sprintf("%s/channels/%s/messages?limit=50", d.baseURL, account)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bot "+d.token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		// Unknown Channel, e.g. deleted or a mistyped ID
+		return nil, fmt.Errorf("HTTP %d: %w", resp.StatusCode, ErrAccountNotFound)
+	default:
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	var items []discordMessage
+	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+		return nil, err
+	}
+
+	messages := make([]domain.Message, 0, len(items))
+	for _, item := range items {
+		if strings.TrimSpace(item.Content) == "" {
+			continue
+		}
+
+		author := item.Author.GlobalName
+		if author == "" {
+			author = item.Author.Username
+		}
+
+		createdAt := item.Timestamp
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+
+		msg := domain.Message{
+			ID:         generateID("discord:" + item.ID),
+			ExternalID: item.ID,
+			Author:     author,
+			Username:   item.Author.Username,
+			Content:    item.Content,
+			Source:     domain.SourceDiscord,
+			CreatedAt:  createdAt,
+		}
+		ExtractEntities(&msg)
+
+		messages = append(messages, msg)
+	}
+
+	return messages, nil
+}
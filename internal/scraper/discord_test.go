@@ -0,0 +1,103 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tokenlaunch/internal/domain"
+)
+
+func discordServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bot secret" {
+			t.Errorf("Authorization = %q, want bot token", got)
+		}
+
+		switch r.URL.Path {
+		case "/channels/100/messages":
+			w.Write([]byte(`[
+  {
+    "id": "200",
+    "channel_id": "100",
+    "content": "stealth launch $ABC https://example.com/abc",
+    "timestamp": "2025-01-06T12:00:00+00:00",
+    "author": {"id": "1", "username": "caller", "global_name": "The Caller"}
+  },
+  {
+    "id": "199",
+    "channel_id": "100",
+    "content": "   ",
+    "timestamp": "2025-01-06T11:00:00+00:00",
+    "author": {"id": "1", "username": "caller"}
+  },
+  {
+    "id": "198",
+    "channel_id": "100",
+    "content": "gm",
+    "timestamp": "2025-01-06T10:00:00+00:00",
+    "author": {"id": "2", "username": "lurker"}
+  }
+]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "Unknown Channel", "code": 10003}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestDiscordScrape(t *testing.T) {
+	srv := discordServer(t)
+	d := NewDiscord("secret", srv.URL)
+
+	msgs, err := d.Scrape(context.Background(), "100")
+	if err != nil {
+		t.Fatalf("scrape: %v", err)
+	}
+	if len(msgs) != 2 {
+		t.Fatalf("got %d messages, want 2 (blank content skipped)", len(msgs))
+	}
+
+	msg := msgs[0]
+	if msg.ID != domain.NewID("discord:200") || msg.ExternalID != "200" {
+		t.Errorf("ID = %s, ExternalID = %s, want discord:200", msg.ID, msg.ExternalID)
+	}
+	if msg.Source != domain.SourceDiscord {
+		t.Errorf("Source = %s, want discord", msg.Source)
+	}
+	if msg.Author != "The Caller" || msg.Username != "caller" {
+		t.Errorf("Author = %q, Username = %q", msg.Author, msg.Username)
+	}
+	if want := time.Date(2025, 1, 6, 12, 0, 0, 0, time.UTC); !msg.CreatedAt.Equal(want) {
+		t.Errorf("CreatedAt = %s, want %s", msg.CreatedAt, want)
+	}
+	if len(msg.Cashtags) != 1 || msg.Cashtags[0] != "ABC" {
+		t.Errorf("Cashtags = %v, want [ABC]", msg.Cashtags)
+	}
+	if len(msg.URLs) != 1 || msg.URLs[0] != "https://example.com/abc" {
+		t.Errorf("URLs = %v", msg.URLs)
+	}
+
+	// Without a global name the username is the author
+	if msgs[1].Author != "lurker" {
+		t.Errorf("Author = %q, want lurker", msgs[1].Author)
+	}
+}
+
+func TestDiscordUnknownChannel(t *testing.T) {
+	srv := discordServer(t)
+	d := NewDiscord("secret", srv.URL)
+
+	_, err := d.Scrape(context.Background(), "404")
+	if !errors.Is(err, ErrAccountNotFound) {
+		t.Fatalf("err = %v, want ErrAccountNotFound", err)
+	}
+}
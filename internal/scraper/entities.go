@@ -0,0 +1,124 @@
+package scraper
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/mmcdole/gofeed"
+
+	"tokenlaunch/internal/domain"
+)
+
+var (
+	cashtagRe = regexp.MustCompile(`(?:^|[^\w$])\$([A-Za-z][A-Za-z0-9_]{0,14})\b`)
+	hashtagRe = regexp.MustCompile(`(?:^|[^\w#])#(\w+)`)
+	mentionRe = regexp.MustCompile(`(?:^|[^\w@])@(\w{1,15})`)
+	urlRe     = regexp.MustCompile(`https?://[^\s<>"]+`)
+	statusRe  = regexp.MustCompile(`^/[^/]+/status/\d+`)
+)
+
+// ExtractEntities fills cashtags, hashtags, mentions and bare URLs found in
+// the message content. Existing URLs are kept and deduplicated.
+func ExtractEntities(msg *domain.Message) {
+	msg.Cashtags = uniqueMatches(cashtagRe, msg.Content, strings.ToUpper)
+	msg.Hashtags = uniqueMatches(hashtagRe, msg.Content, nil)
+	msg.Mentions = uniqueMatches(mentionRe, msg.Content, nil)
+
+	for _, u := range urlRe.FindAllString(msg.Content, -1) {
+		msg.URLs = appendUnique(msg.URLs, strings.TrimRight(u, ".,);"))
+	}
+}
+
+// parseNitterItem turns an RSS item into a message with the full post body,
+// outbound links, media and quoted tweet parsed from the HTML description.
+func parseNitterItem(item *gofeed.Item, instance string, msg *domain.Message) {
+	title := strings.TrimSpace(item.Title)
+	msg.Content = title
+
+	switch {
+	case strings.HasPrefix(title, "RT by @"):
+		msg.IsRetweet = true
+		msg.OriginalAuthor = strings.TrimPrefix(itemCreator(item), "@")
+	case strings.HasPrefix(title, "R to @"):
+		msg.IsReply = true
+		msg.InReplyTo = replyTarget(title)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(item.Description))
+	if err == nil {
+		body := doc.Find("p").First()
+		body.Find("br").ReplaceWithHtml("\n")
+		if text := strings.TrimSpace(body.Text()); text != "" {
+			msg.Content = text
+		}
+
+		doc.Find("a[href]").Each(func(_ int, a *goquery.Selection) {
+			href, _ := a.Attr("href")
+			u, err := url.Parse(href)
+			if err != nil || u.Host == "" {
+				return
+			}
+			if u.Host != instance {
+				msg.URLs = appendUnique(msg.URLs, href)
+				return
+			}
+			if statusRe.MatchString(u.Path) && a.Closest("p").Index() > 0 {
+				msg.QuotedURL = "https://x.com" + u.Path
+			}
+		})
+
+		doc.Find("img[src], video[src], source[src]").Each(func(_ int, m *goquery.Selection) {
+			src, _ := m.Attr("src")
+			msg.Media = appendUnique(msg.Media, src)
+		})
+	}
+
+	ExtractEntities(msg)
+}
+
+// replyTarget extracts "other" from a Nitter title of the form "R to @other: ...".
+func replyTarget(title string) string {
+	rest := strings.TrimPrefix(title, "R to @")
+	if i := strings.Index(rest, ":"); i >= 0 {
+		return rest[:i]
+	}
+	return ""
+}
+
+func uniqueMatches(re *regexp.Regexp, s string, normalize func(string) string) []string {
+	var out []string
+	for _, m := range re.FindAllStringSubmatch(s, -1) {
+		v := m[1]
+		if normalize != nil {
+			v = normalize(v)
+		}
+		out = appendUnique(out, v)
+	}
+	return out
+}
+
+func appendUnique(list []string, v string) []string {
+	if v == "" {
+		return list
+	}
+	for _, existing := range list {
+		if existing == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
+
+// itemCreator returns the raw dc:creator of an item. gofeed can't parse
+// Nitter's "@user" into an author name, so Author is only a fallback.
+func itemCreator(item *gofeed.Item) string {
+	if item.DublinCoreExt != nil && len(item.DublinCoreExt.Creator) > 0 {
+		return item.DublinCoreExt.Creator[0]
+	}
+	if item.Author != nil {
+		return item.Author.Name
+	}
+	return ""
+}
@@ -0,0 +1,233 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tokenlaunch/internal/domain"
+)
+
+// farcasterEpoch is the zero point of Farcaster message timestamps.
+var farcasterEpoch = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type Farcaster struct {
+	hubURL string
+	apiKey string
+	client *http.Client
+
+	mu    sync.Mutex
+	fids  map[string]uint64
+	names map[uint64]string
+}
+
+type farcasterCastID struct {
+	FID  uint64 `json:"fid"`
+	Hash string `json:"hash"`
+}
+
+type farcasterMessage struct {
+	Hash string `json:"hash"`
+	Data struct {
+		Type        string `json:"type"`
+		FID         uint64 `json:"fid"`
+		Timestamp   int64  `json:"timestamp"`
+		CastAddBody *struct {
+			Text         string           `json:"text"`
+			ParentCastID *farcasterCastID `json:"parentCastId"`
+			Embeds       []struct {
+				URL    string           `json:"url"`
+				CastID *farcasterCastID `json:"castId"`
+			} `json:"embeds"`
+		} `json:"castAddBody"`
+	} `json:"data"`
+}
+
+// NewFarcaster reads casts from a Hub's HTTP API. Accounts are FIDs or
+// fname usernames; usernames are resolved once and cached. apiKey is sent
+// for hosted hubs that require one.
+func NewFarcaster(hubURL, apiKey string) *Farcaster {
+	return &Farcaster{
+		hubURL: strings.TrimSuffix(hubURL, "/"),
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 15 * time.Second},
+		fids:   make(map[string]uint64),
+		names:  make(map[uint64]string),
+	}
+}
+
+func (f *Farcaster) Source() domain.Source {
+	return domain.SourceFarcaster
+}
+
+func (f *Farcaster) Scrape(ctx context.Context, account string) ([]domain.Message, error) {
+	fid, err := f.resolveFID(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("fid", strconv.FormatUint(fid, 10))
+	query.Set("pageSize", "50")
+	query.Set("reverse", "true")
+
+	var resp struct {
+		Messages []farcasterMessage `json:"messages"`
+	}
+	if err := f.get(ctx, "/v1/castsByFid", query, &resp); err != nil {
+		return nil, err
+	}
+
+	messages := make([]domain.Message, 0, len(resp.Messages))
+	for _, m := range resp.Messages {
+		body := m.Data.CastAddBody
+		if m.Data.Type != "MESSAGE_TYPE_CAST_ADD" || body == nil {
+			continue
+		}
+
+		msg := domain.Message{
+			ID:         generateID("farcaster:" + m.Hash),
+			ExternalID: m.Hash,
+			Author:     account,
+			Username:   account,
+			Content:    body.Text,
+			Source:     domain.SourceFarcaster,
+			CreatedAt:  farcasterEpoch.Add(time.Duration(m.Data.Timestamp) * time.Second),
+		}
+
+		if body.ParentCastID != nil {
+			msg.IsReply = true
+			if body.ParentCastID.FID == fid {
+				msg.InReplyTo = account
+			} else {
+				msg.InReplyTo = f.resolveName(ctx, body.ParentCastID.FID)
+			}
+		}
+
+		for _, embed := range body.Embeds {
+			switch {
+			case embed.CastID != nil:
+				msg.QuotedURL = "https://warpcast.com/~/conversations/" + embed.CastID.Hash
+			case isImageURL(embed.URL):
+				msg.Media = appendUnique(msg.Media, embed.URL)
+			case embed.URL != "":
+				msg.URLs = appendUnique(msg.URLs, embed.URL)
+			}
+		}
+		ExtractEntities(&msg)
+
+		messages = append(messages, msg)
+	}
+
+	return messages, nil
+}
+
+func (f *Farcaster) resolveFID(ctx context.Context, account string) (uint64, error) {
+	if fid, err := strconv.ParseUint(account, 10, 64); err == nil {
+		return fid, nil
+	}
+
+	name := strings.ToLower(strings.TrimPrefix(account, "@"))
+
+	f.mu.Lock()
+	fid, ok := f.fids[name]
+	f.mu.Unlock()
+	if ok {
+		return fid, nil
+	}
+
+	var proof struct {
+		FID uint64 `json:"fid"`
+	}
+	query := url.Values{}
+	query.Set("name", name)
+	if err := f.get(ctx, "/v1/userNameProofByName", query, &proof); err != nil {
+		return 0, fmt.Errorf("resolve %s: %w", name, err)
+	}
+	if proof.FID == 0 {
+		return 0, fmt.Errorf("resolve %s: %w", name, ErrAccountNotFound)
+	}
+
+	f.mu.Lock()
+	f.fids[name] = proof.FID
+	f.names[proof.FID] = name
+	f.mu.Unlock()
+
+	return proof.FID, nil
+}
+
+// resolveName looks up the username of a FID, falling back to the number.
+func (f *Farcaster) resolveName(ctx context.Context, fid uint64) string {
+	f.mu.Lock()
+	name, ok := f.names[fid]
+	f.mu.Unlock()
+	if ok {
+		return name
+	}
+
+	var resp struct {
+		Data struct {
+			UserDataBody struct {
+				Value string `json:"value"`
+			} `json:"userDataBody"`
+		} `json:"data"`
+	}
+	query := url.Values{}
+	query.Set("fid", strconv.FormatUint(fid, 10))
+	query.Set("user_data_type", "USER_DATA_TYPE_USERNAME")
+	if err := f.get(ctx, "/v1/userDataByFid", query, &resp); err != nil || resp.Data.UserDataBody.Value == "" {
+		return strconv.FormatUint(fid, 10)
+	}
+
+	name = resp.Data.UserDataBody.Value
+	f.mu.Lock()
+	f.names[fid] = name
+	f.mu.Unlock()
+	return name
+}
+
+func (f *Farcaster) get(ctx context.Context, endpoint string, query url.Values, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.hubURL+endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if f.apiKey != "" {
+		req.Header.Set("api_key", f.apiKey)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return fmt.Errorf("HTTP %d: %w", resp.StatusCode, ErrAccountNotFound)
+	default:
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func isImageURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(path.Ext(u.Path)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp":
+		return true
+	}
+	return u.Host == "imagedelivery.net"
+}
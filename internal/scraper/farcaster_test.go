@@ -0,0 +1,165 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tokenlaunch/internal/domain"
+)
+
+const farcasterCastsJSON = `{
+  "messages": [
+    {
+      "hash": "0xaaa",
+      "data": {
+        "type": "MESSAGE_TYPE_CAST_ADD",
+        "fid": 1,
+        "timestamp": 126230400,
+        "castAddBody": {
+          "text": "launching $ABC",
+          "embeds": [
+            {"url": "https://abc.xyz"},
+            {"url": "https://imagedelivery.net/abc/original"},
+            {"castId": {"fid": 3, "hash": "0xccc"}}
+          ]
+        }
+      }
+    },
+    {
+      "hash": "0xbbb",
+      "data": {
+        "type": "MESSAGE_TYPE_CAST_ADD",
+        "fid": 1,
+        "timestamp": 126230300,
+        "castAddBody": {"text": "agreed", "parentCastId": {"fid": 2, "hash": "0x222"}}
+      }
+    },
+    {
+      "hash": "0xbbc",
+      "data": {
+        "type": "MESSAGE_TYPE_CAST_ADD",
+        "fid": 1,
+        "timestamp": 126230200,
+        "castAddBody": {"text": "2/ contract below", "parentCastId": {"fid": 1, "hash": "0x111"}}
+      }
+    },
+    {
+      "hash": "0xddd",
+      "data": {"type": "MESSAGE_TYPE_CAST_REMOVE", "fid": 1, "timestamp": 126230100}
+    }
+  ]
+}`
+
+// farcasterHub stands in for a Hub's HTTP API. It knows fname alice as FID 1
+// and FID 2 as bob, and counts username resolutions.
+func farcasterHub(t *testing.T, resolved *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/v1/userNameProofByName":
+			resolved.Add(1)
+			if q.Get("name") != "alice" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(`{"name": "alice", "fid": 1}`))
+		case "/v1/castsByFid":
+			if q.Get("fid") != "1" {
+				w.Write([]byte(`{"messages": []}`))
+				return
+			}
+			w.Write([]byte(farcasterCastsJSON))
+		case "/v1/userDataByFid":
+			if q.Get("fid") != "2" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(`{"data": {"userDataBody": {"type": "USER_DATA_TYPE_USERNAME", "value": "bob"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestFarcasterScrape(t *testing.T) {
+	var resolved atomic.Int32
+	srv := farcasterHub(t, &resolved)
+	f := NewFarcaster(srv.URL, "")
+
+	msgs, err := f.Scrape(context.Background(), "@Alice")
+	if err != nil {
+		t.Fatalf("scrape: %v", err)
+	}
+	if len(msgs) != 3 {
+		t.Fatalf("got %d messages, want 3 (cast removal skipped)", len(msgs))
+	}
+
+	cast, reply, selfReply := msgs[0], msgs[1], msgs[2]
+
+	if cast.ID != domain.NewID("farcaster:0xaaa") || cast.ExternalID != "0xaaa" {
+		t.Errorf("ID = %s, ExternalID = %s, want farcaster:0xaaa", cast.ID, cast.ExternalID)
+	}
+	if cast.Source != domain.SourceFarcaster || cast.Username != "@Alice" {
+		t.Errorf("Source = %s, Username = %q", cast.Source, cast.Username)
+	}
+	if want := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC); !cast.CreatedAt.Equal(want) {
+		t.Errorf("CreatedAt = %s, want %s", cast.CreatedAt, want)
+	}
+	if len(cast.URLs) != 1 || cast.URLs[0] != "https://abc.xyz" {
+		t.Errorf("URLs = %v", cast.URLs)
+	}
+	if len(cast.Media) != 1 || cast.Media[0] != "https://imagedelivery.net/abc/original" {
+		t.Errorf("Media = %v", cast.Media)
+	}
+	if cast.QuotedURL != "https://warpcast.com/~/conversations/0xccc" {
+		t.Errorf("QuotedURL = %q", cast.QuotedURL)
+	}
+	if len(cast.Cashtags) != 1 || cast.Cashtags[0] != "ABC" {
+		t.Errorf("Cashtags = %v", cast.Cashtags)
+	}
+
+	if !reply.IsReply || reply.InReplyTo != "bob" {
+		t.Errorf("reply: IsReply = %v, InReplyTo = %q, want bob", reply.IsReply, reply.InReplyTo)
+	}
+	if !selfReply.IsSelfReply() {
+		t.Errorf("self-reply: InReplyTo = %q, want the account", selfReply.InReplyTo)
+	}
+
+	// The FID is cached after the first resolution
+	if _, err := f.Scrape(context.Background(), "alice"); err != nil {
+		t.Fatalf("second scrape: %v", err)
+	}
+	if n := resolved.Load(); n != 1 {
+		t.Errorf("resolved the username %d times, want 1", n)
+	}
+
+	// Numeric accounts are FIDs and aren't resolved
+	msgs, err = f.Scrape(context.Background(), "1")
+	if err != nil || len(msgs) != 3 {
+		t.Fatalf("scrape by FID: %d messages, %v", len(msgs), err)
+	}
+	if n := resolved.Load(); n != 1 {
+		t.Errorf("resolved a FID account, %d resolutions", n)
+	}
+}
+
+func TestFarcasterUnknownUsername(t *testing.T) {
+	var resolved atomic.Int32
+	srv := farcasterHub(t, &resolved)
+	f := NewFarcaster(srv.URL, "")
+
+	_, err := f.Scrape(context.Background(), "nobody")
+	if !errors.Is(err, ErrAccountNotFound) {
+		t.Fatalf("err = %v, want ErrAccountNotFound", err)
+	}
+}
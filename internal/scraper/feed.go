@@ -0,0 +1,212 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/mmcdole/gofeed"
+
+	"tokenlaunch/internal/domain"
+)
+
+// maxFeedContent caps how much of a long blog post is kept as message content.
+const maxFeedContent = 4000
+
+type Feed struct {
+	cursors CursorStore
+	client  *http.Client
+	parser  *gofeed.Parser
+}
+
+// NewFeed reads arbitrary RSS/Atom/JSON feeds. Accounts are full feed URLs.
+// When cursors is non-nil, feeds are fetched conditionally and only entries
+// newer than the stored cursor are returned.
+func NewFeed(cursors CursorStore) *Feed {
+	return &Feed{
+		cursors: cursors,
+		client:  &http.Client{Timeout: 15 * time.Second},
+		parser:  gofeed.NewParser(),
+	}
+}
+
+func (f *Feed) Source() domain.Source {
+	return domain.SourceFeed
+}
+
+func (f *Feed) ResetCursor(ctx context.Context, account string) error {
+	if f.cursors == nil {
+		return nil
+	}
+	return f.cursors.DeleteCursor(ctx, domain.SourceFeed, account)
+}
+
+func (f *Feed) Scrape(ctx context.Context, account string) ([]domain.Message, error) {
+	cursor := &domain.Cursor{}
+	if f.cursors != nil {
+		stored, err := f.cursors.GetCursor(ctx, domain.SourceFeed, account)
+		if err != nil {
+			return nil, fmt.Errorf("load cursor: %w", err)
+		}
+		if stored != nil {
+			cursor = stored
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, account, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", "curl/8.0")
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/feed+json, application/xml, text/xml, */*")
+	if cursor.ETag != "" {
+		req.Header.Set("If-None-Match", cursor.ETag)
+	}
+	if cursor.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cursor.LastModified)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, nil
+	case http.StatusNotFound, http.StatusGone:
+		return nil, ErrAccountNotFound
+	default:
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	feed, err := f.parser.Parse(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	next := domain.Cursor{
+		LastID:        cursor.LastID,
+		LastPublished: cursor.LastPublished,
+		ETag:          resp.Header.Get("ETag"),
+		LastModified:  resp.Header.Get("Last-Modified"),
+	}
+
+	messages := make([]domain.Message, 0, len(feed.Items))
+	for _, item := range feed.Items {
+		guid := itemKey(item)
+
+		published := item.PublishedParsed
+		if published == nil {
+			published = item.UpdatedParsed
+		}
+
+		if published != nil {
+			if !published.After(cursor.LastPublished) {
+				continue
+			}
+		} else if guid == cursor.LastID {
+			continue
+		}
+
+		createdAt := time.Now()
+		if published != nil {
+			createdAt = *published
+			if createdAt.After(next.LastPublished) {
+				next.LastPublished = createdAt
+				next.LastID = guid
+			}
+		}
+
+		author := feed.Title
+		if item.Author != nil && item.Author.Name != "" {
+			author = item.Author.Name
+		}
+
+		msg := domain.Message{
+			// GUIDs are only unique within their feed
+			ID:         generateID("feed:" + account + ":" + guid),
+			ExternalID: guid,
+			Author:     author,
+			Username:   account,
+			Source:     domain.SourceFeed,
+			CreatedAt:  createdAt,
+		}
+		parseFeedItem(item, &msg)
+
+		messages = append(messages, msg)
+	}
+
+	if f.cursors != nil {
+		if err := f.cursors.SetCursor(ctx, domain.SourceFeed, account, next); err != nil {
+			return nil, fmt.Errorf("save cursor: %w", err)
+		}
+	}
+
+	return messages, nil
+}
+
+// itemKey identifies an entry within its feed by GUID, then link, then title
+// and date for feeds that set neither.
+func itemKey(item *gofeed.Item) string {
+	if item.GUID != "" {
+		return item.GUID
+	}
+	if item.Link != "" {
+		return item.Link
+	}
+	return item.Title + "@" + item.Published
+}
+
+// parseFeedItem builds plain-text content from the entry title and HTML body
+// and collects its links and images.
+func parseFeedItem(item *gofeed.Item, msg *domain.Message) {
+	body := item.Content
+	if body == "" {
+		body = item.Description
+	}
+
+	text := ""
+	if doc, err := goquery.NewDocumentFromReader(strings.NewReader(body)); err == nil {
+		doc.Find("br").ReplaceWithHtml("\n")
+		doc.Find("p, li, h1, h2, h3, h4").Each(func(_ int, s *goquery.Selection) {
+			s.AppendHtml("\n")
+		})
+		text = strings.TrimSpace(doc.Text())
+
+		doc.Find("a[href]").Each(func(_ int, a *goquery.Selection) {
+			href, _ := a.Attr("href")
+			if strings.HasPrefix(href, "http") {
+				msg.URLs = appendUnique(msg.URLs, href)
+			}
+		})
+		doc.Find("img[src]").Each(func(_ int, img *goquery.Selection) {
+			src, _ := img.Attr("src")
+			msg.Media = appendUnique(msg.Media, src)
+		})
+	}
+
+	msg.Content = strings.TrimSpace(item.Title)
+	if text != "" {
+		if msg.Content != "" {
+			msg.Content += "\n\n"
+		}
+		msg.Content += text
+	}
+	if runes := []rune(msg.Content); len(runes) > maxFeedContent {
+		msg.Content = string(runes[:maxFeedContent])
+	}
+
+	msg.URLs = appendUnique(msg.URLs, item.Link)
+	if item.Image != nil {
+		msg.Media = appendUnique(msg.Media, item.Image.URL)
+	}
+
+	ExtractEntities(msg)
+}
@@ -0,0 +1,52 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFeedIDsScopedToFeed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>%[1]s</title>
+    <item>
+      <title>Announcing our token</title>
+      <guid isPermaLink="false">1</guid>
+      <pubDate>Mon, 06 Jan 2025 12:00:00 GMT</pubDate>
+    </item>
+    <item>
+      <title>Roadmap</title>
+      <pubDate>Sun, 05 Jan 2025 12:00:00 GMT</pubDate>
+    </item>
+    <item>
+      <title>Weekly update</title>
+      <pubDate>Sat, 04 Jan 2025 12:00:00 GMT</pubDate>
+    </item>
+  </channel>
+</rss>`, r.URL.Path)
+	}))
+	t.Cleanup(srv.Close)
+
+	f := NewFeed(nil)
+	ids := make(map[string]string)
+	for _, path := range []string{"/a.xml", "/b.xml"} {
+		msgs, err := f.Scrape(context.Background(), srv.URL+path)
+		if err != nil {
+			t.Fatalf("scrape %s: %v", path, err)
+		}
+		if len(msgs) != 3 {
+			t.Fatalf("scrape %s: got %d messages, want 3", path, len(msgs))
+		}
+		for _, msg := range msgs {
+			if other, ok := ids[msg.ID]; ok {
+				t.Errorf("%s %q collides with %s", path, msg.ExternalID, other)
+			}
+			ids[msg.ID] = path + " " + msg.ExternalID
+		}
+	}
+}
@@ -2,9 +2,12 @@ package scraper
 
 import (
 	"context"
-	"crypto/md5"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/mmcdole/gofeed"
@@ -12,22 +15,86 @@ import (
 	"tokenlaunch/internal/domain"
 )
 
+// maxAttempts bounds how many instances a single Scrape call tries.
+const maxAttempts = 3
+
+var (
+	ErrAccountNotFound = errors.New("account not found")
+	ErrNoInstances     = errors.New("no nitter instances configured")
+)
+
 type Nitter struct {
-	instance string
-	client   *http.Client
-	parser   *gofeed.Parser
+	pool    *Pool
+	cursors CursorStore
+	client  *http.Client
+	parser  *gofeed.Parser
 }
 
-func NewNitter(instance string) *Nitter {
+// NewNitter creates a scraper over a pool of instances. When cursors is
+// non-nil, feeds are fetched conditionally and only items that weren't in the
+// previous read of the account's feed are returned.
+func NewNitter(instances []string, cursors CursorStore) *Nitter {
 	return &Nitter{
-		instance: instance,
-		client:   &http.Client{Timeout: 15 * time.Second},
-		parser:   gofeed.NewParser(),
+		pool:    NewPool(instances),
+		cursors: cursors,
+		client:  &http.Client{Timeout: 15 * time.Second},
+		parser:  gofeed.NewParser(),
 	}
 }
 
+func (n *Nitter) Source() domain.Source {
+	return domain.SourceTwitter
+}
+
+func (n *Nitter) Health() []domain.InstanceHealth {
+	return n.pool.Health()
+}
+
+func (n *Nitter) ResetCursor(ctx context.Context, account string) error {
+	if n.cursors == nil {
+		return nil
+	}
+	return n.cursors.DeleteCursor(ctx, domain.SourceTwitter, account)
+}
+
+// Scrape tries instances in order of health until one returns the feed.
 func (n *Nitter) Scrape(ctx context.Context, account string) ([]domain.Message, error) {
-	url := fmt.Sprintf("https://%s/%s/rss", n.instance, account)
+	candidates := n.pool.Candidates()
+	if len(candidates) == 0 {
+		return nil, ErrNoInstances
+	}
+	if len(candidates) > maxAttempts {
+		candidates = candidates[:maxAttempts]
+	}
+
+	cursor := &domain.Cursor{}
+	if n.cursors != nil {
+		stored, err := n.cursors.GetCursor(ctx, domain.SourceTwitter, account)
+		if err != nil {
+			return nil, fmt.Errorf("load cursor: %w", err)
+		}
+		if stored != nil {
+			cursor = stored
+		}
+	}
+
+	var lastErr error
+	for _, instance := range candidates {
+		messages, err := n.scrapeInstance(ctx, instance, account, cursor)
+		if err == nil {
+			return messages, nil
+		}
+		if errors.Is(err, ErrAccountNotFound) || ctx.Err() != nil {
+			return nil, err
+		}
+		lastErr = err
+	}
+
+	return nil, lastErr
+}
+
+func (n *Nitter) scrapeInstance(ctx context.Context, instance, account string, cursor *domain.Cursor) ([]domain.Message, error) {
+	url := fmt.Sprintf("https://%s/%s/rss", instance, account)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
@@ -37,43 +104,127 @@ func (n *Nitter) Scrape(ctx context.Context, account string) ([]domain.Message,
 	req.Header.Set("User-Agent", "curl/8.0")
 	req.Header.Set("Accept", "application/rss+xml, application/xml, text/xml, */*")
 
+	// Validators are only meaningful to the instance that issued them
+	if cursor.Instance == instance {
+		if cursor.ETag != "" {
+			req.Header.Set("If-None-Match", cursor.ETag)
+		}
+		if cursor.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cursor.LastModified)
+		}
+	}
+
+	start := time.Now()
 	resp, err := n.client.Do(req)
 	if err != nil {
-		return nil, err
+		if ctx.Err() == nil {
+			n.pool.Failure(instance, time.Since(start), err)
+		}
+		return nil, fmt.Errorf("%s: %w", instance, err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		n.pool.Success(instance, time.Since(start))
+		return nil, nil
+	case resp.StatusCode == http.StatusTooManyRequests:
+		n.pool.RateLimited(instance, retryAfter(resp))
+		return nil, fmt.Errorf("%s: HTTP %d", instance, resp.StatusCode)
+	case resp.StatusCode == http.StatusNotFound:
+		n.pool.Success(instance, time.Since(start))
+		return nil, fmt.Errorf("%s: %w", instance, ErrAccountNotFound)
+	case resp.StatusCode != http.StatusOK:
+		err := fmt.Errorf("HTTP %d", resp.StatusCode)
+		n.pool.Failure(instance, time.Since(start), err)
+		return nil, fmt.Errorf("%s: %w", instance, err)
 	}
 
 	feed, err := n.parser.Parse(resp.Body)
 	if err != nil {
-		return nil, err
+		n.pool.Failure(instance, time.Since(start), err)
+		return nil, fmt.Errorf("%s: %w", instance, err)
+	}
+	n.pool.Success(instance, time.Since(start))
+
+	next := domain.Cursor{
+		Instance:     instance,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+
+	// Feeds aren't in time order: pinned tweets stay on top and retweets
+	// carry the original's date. Items are compared against the previous
+	// read instead of a timestamp.
+	seen := make(map[string]bool, len(cursor.SeenIDs))
+	for _, id := range cursor.SeenIDs {
+		seen[id] = true
 	}
 
 	messages := make([]domain.Message, 0, len(feed.Items))
 	for _, item := range feed.Items {
+		id := statusID(item)
+		next.SeenIDs = append(next.SeenIDs, id)
+		if seen[id] {
+			continue
+		}
+
 		createdAt := time.Now()
 		if item.PublishedParsed != nil {
 			createdAt = *item.PublishedParsed
 		}
 
-		messages = append(messages, domain.Message{
-			ID:         generateID(item.GUID),
-			ExternalID: item.GUID,
+		msg := domain.Message{
+			ID:         generateID(string(domain.SourceTwitter) + ":" + id),
+			ExternalID: id,
 			Author:     feed.Title,
 			Username:   account,
-			Content:    item.Title,
 			Source:     domain.SourceTwitter,
 			CreatedAt:  createdAt,
-		})
+		}
+		parseNitterItem(item, instance, &msg)
+		if msg.IsRetweet {
+			// A retweet links the original tweet, keep it apart from the original
+			msg.ID = generateID(string(domain.SourceTwitter) + ":retweet:" + account + ":" + id)
+		}
+
+		messages = append(messages, msg)
+	}
+
+	if n.cursors != nil {
+		if err := n.cursors.SetCursor(ctx, domain.SourceTwitter, account, next); err != nil {
+			return nil, fmt.Errorf("save cursor: %w", err)
+		}
 	}
 
 	return messages, nil
 }
 
+// statusID extracts the tweet ID from an item's /<user>/status/<id> URL.
+// GUIDs embed the instance host, so they differ for the same tweet across
+// instances and can't identify it.
+func statusID(item *gofeed.Item) string {
+	for _, raw := range []string{item.GUID, item.Link} {
+		u, err := url.Parse(raw)
+		if err != nil {
+			continue
+		}
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if len(parts) >= 3 && parts[len(parts)-2] == "status" {
+			return parts[len(parts)-1]
+		}
+	}
+	return item.GUID
+}
+
+func retryAfter(resp *http.Response) time.Duration {
+	secs, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || secs <= 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
 func generateID(guid string) string {
-	hash := md5.Sum([]byte(guid))
-	return fmt.Sprintf("%x", hash)[:12]
+	return domain.NewID(guid)
 }
@@ -0,0 +1,153 @@
+package scraper
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"tokenlaunch/internal/domain"
+)
+
+const nitterLaunch = `
+    <item>
+      <title>launching $ABC today</title>
+      <description>&lt;p&gt;launching $ABC today&lt;/p&gt;</description>
+      <pubDate>Mon, 06 Jan 2025 12:00:00 GMT</pubDate>
+      <guid>https://%[1]s/alice/status/1876000000000000001#m</guid>
+      <link>https://%[1]s/alice/status/1876000000000000001#m</link>
+    </item>`
+
+const nitterRetweet = `
+    <item>
+      <title>RT by @alice: $XYZ is live</title>
+      <dc:creator>@bob</dc:creator>
+      <description>&lt;p&gt;$XYZ is live&lt;/p&gt;</description>
+      <pubDate>Fri, 03 Jan 2025 09:00:00 GMT</pubDate>
+      <guid>https://%[1]s/bob/status/1875000000000000002#m</guid>
+      <link>https://%[1]s/bob/status/1875000000000000002#m</link>
+    </item>`
+
+// nitterServer serves alice's feed with the items *items points to, in which
+// %[1]s is replaced by the instance host.
+func nitterServer(t *testing.T, items *string) (*httptest.Server, string) {
+	t.Helper()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel>
+    <title>Alice / @alice</title>`+*items+`
+  </channel>
+</rss>`, r.Host)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, strings.TrimPrefix(srv.URL, "https://")
+}
+
+func newTestNitter(instances []string, cursors CursorStore) *Nitter {
+	n := NewNitter(instances, cursors)
+	n.client = &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+	return n
+}
+
+// memoryCursors is a CursorStore for tests.
+type memoryCursors map[string]domain.Cursor
+
+func (m memoryCursors) GetCursor(_ context.Context, source domain.Source, account string) (*domain.Cursor, error) {
+	c, ok := m[string(source)+":"+account]
+	if !ok {
+		return nil, nil
+	}
+	return &c, nil
+}
+
+func (m memoryCursors) SetCursor(_ context.Context, source domain.Source, account string, cursor domain.Cursor) error {
+	m[string(source)+":"+account] = cursor
+	return nil
+}
+
+func (m memoryCursors) DeleteCursor(_ context.Context, source domain.Source, account string) error {
+	delete(m, string(source)+":"+account)
+	return nil
+}
+
+func TestNitterIDStableAcrossInstances(t *testing.T) {
+	items := nitterLaunch
+	_, first := nitterServer(t, &items)
+	_, second := nitterServer(t, &items)
+
+	n := newTestNitter([]string{first, second}, nil)
+
+	var ids []string
+	for _, instance := range []string{first, second} {
+		msgs, err := n.scrapeInstance(context.Background(), instance, "alice", &domain.Cursor{})
+		if err != nil {
+			t.Fatalf("scrape %s: %v", instance, err)
+		}
+		if len(msgs) != 1 {
+			t.Fatalf("scrape %s: got %d messages, want 1", instance, len(msgs))
+		}
+		if msgs[0].ExternalID != "1876000000000000001" {
+			t.Errorf("scrape %s: ExternalID = %q, want status ID", instance, msgs[0].ExternalID)
+		}
+		ids = append(ids, msgs[0].ID)
+	}
+
+	if ids[0] != ids[1] {
+		t.Errorf("same tweet got different IDs across instances: %s vs %s", ids[0], ids[1])
+	}
+	if want := domain.NewID("twitter:1876000000000000001"); ids[0] != want {
+		t.Errorf("ID = %s, want %s", ids[0], want)
+	}
+}
+
+func TestNitterReturnsOlderItemsNewToTheFeed(t *testing.T) {
+	items := nitterLaunch
+	_, instance := nitterServer(t, &items)
+
+	n := newTestNitter([]string{instance}, memoryCursors{})
+	ctx := context.Background()
+
+	msgs, err := n.Scrape(ctx, "alice")
+	if err != nil {
+		t.Fatalf("first scrape: %v", err)
+	}
+	if len(msgs) != 1 {
+		t.Fatalf("first scrape: got %d messages, want 1", len(msgs))
+	}
+
+	// A retweet of an older tweet lands on top with the original's date
+	items = nitterRetweet + nitterLaunch
+
+	msgs, err = n.Scrape(ctx, "alice")
+	if err != nil {
+		t.Fatalf("second scrape: %v", err)
+	}
+	if len(msgs) != 1 {
+		t.Fatalf("second scrape: got %d messages, want only the retweet", len(msgs))
+	}
+	if !msgs[0].IsRetweet || msgs[0].ExternalID != "1875000000000000002" {
+		t.Errorf("got %+v, want the retweet of 1875000000000000002", msgs[0])
+	}
+	if msgs[0].ID == domain.NewID("twitter:1875000000000000002") {
+		t.Errorf("retweet shares the ID of bob's original tweet")
+	}
+	if msgs[0].OriginalAuthor != "bob" {
+		t.Errorf("OriginalAuthor = %q, want bob", msgs[0].OriginalAuthor)
+	}
+
+	msgs, err = n.Scrape(ctx, "alice")
+	if err != nil {
+		t.Fatalf("third scrape: %v", err)
+	}
+	if len(msgs) != 0 {
+		t.Errorf("third scrape: got %d messages, want none", len(msgs))
+	}
+}
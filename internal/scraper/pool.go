@@ -0,0 +1,177 @@
+package scraper
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"tokenlaunch/internal/domain"
+)
+
+const (
+	baseCooldown      = 30 * time.Second
+	maxCooldown       = 10 * time.Minute
+	rateLimitCooldown = 2 * time.Minute
+)
+
+// Pool tracks the health of a set of instances and orders them so callers
+// try the healthiest first. Failing instances are put on an exponential
+// cooldown; rate-limited ones honour Retry-After when the server sends it.
+type Pool struct {
+	mu        sync.Mutex
+	instances []*instanceState
+}
+
+type instanceState struct {
+	host          string
+	requests      int
+	successes     int
+	failures      int
+	rateLimited   int
+	consecutive   int
+	latency       time.Duration
+	cooldownUntil time.Time
+	lastError     string
+	lastSuccess   time.Time
+}
+
+func NewPool(instances []string) *Pool {
+	p := &Pool{}
+	seen := make(map[string]bool)
+	for _, host := range instances {
+		host = strings.TrimSpace(host)
+		if host == "" || seen[host] {
+			continue
+		}
+		seen[host] = true
+		p.instances = append(p.instances, &instanceState{host: host})
+	}
+	return p
+}
+
+// Candidates returns instances ordered by preference: available ones by score,
+// then those in cooldown by the time they become available again.
+func (p *Pool) Candidates() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	states := make([]*instanceState, len(p.instances))
+	copy(states, p.instances)
+
+	sort.SliceStable(states, func(i, j int) bool {
+		a, b := states[i], states[j]
+		aReady, bReady := !now.Before(a.cooldownUntil), !now.Before(b.cooldownUntil)
+		if aReady != bReady {
+			return aReady
+		}
+		if !aReady {
+			return a.cooldownUntil.Before(b.cooldownUntil)
+		}
+		return a.score() > b.score()
+	})
+
+	hosts := make([]string, len(states))
+	for i, s := range states {
+		hosts[i] = s.host
+	}
+	return hosts
+}
+
+func (p *Pool) Success(host string, latency time.Duration) {
+	p.update(host, func(s *instanceState) {
+		s.requests++
+		s.successes++
+		s.consecutive = 0
+		s.cooldownUntil = time.Time{}
+		s.lastSuccess = time.Now()
+		s.observe(latency)
+	})
+}
+
+func (p *Pool) Failure(host string, latency time.Duration, err error) {
+	p.update(host, func(s *instanceState) {
+		s.requests++
+		s.failures++
+		s.consecutive++
+		s.lastError = err.Error()
+		s.observe(latency)
+
+		cooldown := baseCooldown << (s.consecutive - 1)
+		if cooldown > maxCooldown || cooldown <= 0 {
+			cooldown = maxCooldown
+		}
+		s.cooldownUntil = time.Now().Add(cooldown)
+	})
+}
+
+func (p *Pool) RateLimited(host string, retryAfter time.Duration) {
+	p.update(host, func(s *instanceState) {
+		s.requests++
+		s.rateLimited++
+		s.lastError = "rate limited"
+
+		if retryAfter <= 0 {
+			retryAfter = rateLimitCooldown
+		}
+		s.cooldownUntil = time.Now().Add(retryAfter)
+	})
+}
+
+func (p *Pool) Health() []domain.InstanceHealth {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	health := make([]domain.InstanceHealth, len(p.instances))
+	for i, s := range p.instances {
+		var rate float64
+		if s.requests > 0 {
+			rate = float64(s.successes) / float64(s.requests)
+		}
+		health[i] = domain.InstanceHealth{
+			Instance:      s.host,
+			Healthy:       !now.Before(s.cooldownUntil),
+			Score:         s.score(),
+			Requests:      s.requests,
+			Successes:     s.successes,
+			Failures:      s.failures,
+			RateLimited:   s.rateLimited,
+			SuccessRate:   rate,
+			Latency:       s.latency,
+			CooldownUntil: s.cooldownUntil,
+			LastError:     s.lastError,
+			LastSuccess:   s.lastSuccess,
+		}
+	}
+	return health
+}
+
+func (p *Pool) update(host string, fn func(s *instanceState)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, s := range p.instances {
+		if s.host == host {
+			fn(s)
+			return
+		}
+	}
+}
+
+// observe folds a request latency into an exponentially weighted average.
+func (s *instanceState) observe(latency time.Duration) {
+	if s.latency == 0 {
+		s.latency = latency
+		return
+	}
+	s.latency = (s.latency*4 + latency) / 5
+}
+
+// score favours a high success rate and low latency. Untried instances start
+// at a neutral success rate so they still get picked.
+func (s *instanceState) score() float64 {
+	rate := float64(s.successes+1) / float64(s.requests+2)
+	return rate / (1 + s.latency.Seconds())
+}
@@ -0,0 +1,280 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"tokenlaunch/internal/domain"
+)
+
+const (
+	redditAPI       = "https://www.reddit.com"
+	redditUserAgent = "tokenlaunch/1.0"
+
+	// maxRedditPages bounds how far back a single Scrape pages to catch up
+	// with the cursor after a long pause.
+	maxRedditPages = 5
+)
+
+type Reddit struct {
+	baseURL   string
+	userAgent string
+	cursors   CursorStore
+	client    *http.Client
+}
+
+type redditListing struct {
+	Data struct {
+		After    string `json:"after"`
+		Children []struct {
+			Kind string      `json:"kind"`
+			Data redditThing `json:"data"`
+		} `json:"children"`
+	} `json:"data"`
+}
+
+type redditThing struct {
+	Name       string  `json:"name"`
+	Author     string  `json:"author"`
+	Subreddit  string  `json:"subreddit"`
+	Title      string  `json:"title"`
+	Selftext   string  `json:"selftext"`
+	Body       string  `json:"body"`
+	URL        string  `json:"url"`
+	IsSelf     bool    `json:"is_self"`
+	PostHint   string  `json:"post_hint"`
+	Permalink  string  `json:"permalink"`
+	ParentID   string  `json:"parent_id"`
+	LinkAuthor string  `json:"link_author"`
+	CreatedUTC float64 `json:"created_utc"`
+}
+
+// NewReddit reads subreddit and user JSON listings. Accounts are written as
+// r/<subreddit> or u/<user>; a bare name is treated as a subreddit. When
+// cursors is non-nil only things newer than the stored cursor are returned.
+func NewReddit(baseURL, userAgent string, cursors CursorStore) *Reddit {
+	if baseURL == "" {
+		baseURL = redditAPI
+	}
+	if userAgent == "" {
+		userAgent = redditUserAgent
+	}
+
+	return &Reddit{
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		userAgent: userAgent,
+		cursors:   cursors,
+		client:    &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (r *Reddit) Source() domain.Source {
+	return domain.SourceReddit
+}
+
+func (r *Reddit) ResetCursor(ctx context.Context, account string) error {
+	if r.cursors == nil {
+		return nil
+	}
+	if err := r.cursors.DeleteCursor(ctx, domain.SourceReddit, account); err != nil {
+		return err
+	}
+	return r.cursors.DeleteCursor(ctx, domain.SourceReddit, account+"#comments")
+}
+
+// Scrape returns new posts and new top-level comments for a subreddit or user.
+func (r *Reddit) Scrape(ctx context.Context, account string) ([]domain.Message, error) {
+	postsPath, commentsPath, err := redditPaths(account)
+	if err != nil {
+		return nil, err
+	}
+
+	postsKey, commentsKey := account, account+"#comments"
+
+	posts, postsCursor, err := r.listing(ctx, postsKey, postsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, commentsCursor, err := r.listing(ctx, commentsKey, commentsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only advance once both listings were read, so a failed comments
+	// request doesn't drop the posts already fetched
+	if err := r.saveCursor(ctx, postsKey, postsCursor); err != nil {
+		return nil, err
+	}
+	if err := r.saveCursor(ctx, commentsKey, commentsCursor); err != nil {
+		return nil, err
+	}
+
+	messages := make([]domain.Message, 0, len(posts)+len(comments))
+	for _, t := range posts {
+		msg := r.message(t)
+		msg.Content = strings.TrimSpace(t.Title + "\n\n" + t.Selftext)
+		if !t.IsSelf && t.URL != "" {
+			if t.PostHint == "image" {
+				msg.Media = appendUnique(msg.Media, t.URL)
+			} else {
+				msg.URLs = appendUnique(msg.URLs, t.URL)
+			}
+		}
+		ExtractEntities(&msg)
+		messages = append(messages, msg)
+	}
+
+	for _, t := range comments {
+		// Only top-level comments, which answer the post (t3_) directly
+		if !strings.HasPrefix(t.ParentID, "t3_") {
+			continue
+		}
+		msg := r.message(t)
+		msg.Content = strings.TrimSpace(t.Body)
+		msg.IsReply = true
+		msg.InReplyTo = t.LinkAuthor
+		ExtractEntities(&msg)
+		messages = append(messages, msg)
+	}
+
+	return messages, nil
+}
+
+func (r *Reddit) message(t redditThing) domain.Message {
+	return domain.Message{
+		ID:         generateID("reddit:" + t.Name),
+		ExternalID: t.Name,
+		Author:     "r/" + t.Subreddit,
+		Username:   t.Author,
+		Source:     domain.SourceReddit,
+		CreatedAt:  time.Unix(int64(t.CreatedUTC), 0),
+		URLs:       []string{r.baseURL + t.Permalink},
+	}
+}
+
+// listing pages through a listing with Reddit's after cursor until it reaches
+// things at or before the stored cursor. On the first run only one page is
+// read. It returns the cursor advanced to the newest thing seen, or nil when
+// it didn't move; saving it is up to the caller.
+func (r *Reddit) listing(ctx context.Context, key, path string) ([]redditThing, *domain.Cursor, error) {
+	cursor := &domain.Cursor{}
+	if r.cursors != nil {
+		stored, err := r.cursors.GetCursor(ctx, domain.SourceReddit, key)
+		if err != nil {
+			return nil, nil, fmt.Errorf("load cursor: %w", err)
+		}
+		if stored != nil {
+			cursor = stored
+		}
+	}
+
+	next := *cursor
+	var things []redditThing
+	after := ""
+
+pages:
+	for page := 0; page < maxRedditPages; page++ {
+		listing, err := r.fetch(ctx, path, after)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, child := range listing.Data.Children {
+			t := child.Data
+			created := time.Unix(int64(t.CreatedUTC), 0)
+			if t.Name == cursor.LastID || !created.After(cursor.LastPublished) {
+				break pages
+			}
+			if created.After(next.LastPublished) {
+				next.LastPublished = created
+				next.LastID = t.Name
+			}
+			things = append(things, t)
+		}
+
+		after = listing.Data.After
+		if after == "" || cursor.LastID == "" {
+			break
+		}
+	}
+
+	if next.LastID == cursor.LastID {
+		return things, nil, nil
+	}
+	return things, &next, nil
+}
+
+func (r *Reddit) saveCursor(ctx context.Context, key string, cursor *domain.Cursor) error {
+	if r.cursors == nil || cursor == nil {
+		return nil
+	}
+	if err := r.cursors.SetCursor(ctx, domain.SourceReddit, key, *cursor); err != nil {
+		return fmt.Errorf("save cursor: %w", err)
+	}
+	return nil
+}
+
+func (r *Reddit) fetch(ctx context.Context, path, after string) (*redditListing, error) {
+	query := url.Values{}
+	query.Set("limit", "100")
+	query.Set("raw_json", "1")
+	if after != "" {
+		query.Set("after", after)
+	}
+	endpoint := fmt.Sprintf("%s%s.json?%s", r.baseURL, path, query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", r.userAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusForbidden:
+		// Banned, private and missing subreddits/users
+		return nil, fmt.Errorf("HTTP %d: %w", resp.StatusCode, ErrAccountNotFound)
+	default:
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	var listing redditListing
+	if err := json.NewDecoder(resp.Body).Decode(&listing); err != nil {
+		return nil, err
+	}
+	return &listing, nil
+}
+
+// redditPaths maps an account to its posts and comments listing paths.
+func redditPaths(account string) (posts, comments string, err error) {
+	account = strings.Trim(account, "/ ")
+	kind, name, found := strings.Cut(account, "/")
+	if !found {
+		kind, name = "r", account
+	}
+	if name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("invalid reddit account %q", account)
+	}
+
+	switch strings.ToLower(kind) {
+	case "r":
+		return "/r/" + name + "/new", "/r/" + name + "/comments", nil
+	case "u", "user":
+		return "/user/" + name + "/submitted", "/user/" + name + "/comments", nil
+	default:
+		return "", "", fmt.Errorf("invalid reddit account %q", account)
+	}
+}
@@ -7,5 +7,25 @@ import (
 )
 
 type Scraper interface {
+	Source() domain.Source
 	Scrape(ctx context.Context, account string) ([]domain.Message, error)
 }
+
+// HealthReporter is implemented by scrapers that spread requests over a pool
+// of upstream instances.
+type HealthReporter interface {
+	Health() []domain.InstanceHealth
+}
+
+// CursorStore persists per-account polling cursors between runs.
+type CursorStore interface {
+	GetCursor(ctx context.Context, source domain.Source, account string) (*domain.Cursor, error)
+	SetCursor(ctx context.Context, source domain.Source, account string, cursor domain.Cursor) error
+	DeleteCursor(ctx context.Context, source domain.Source, account string) error
+}
+
+// CursorResetter is implemented by scrapers that only return items newer than
+// a stored cursor. Resetting makes the next Scrape return the full window again.
+type CursorResetter interface {
+	ResetCursor(ctx context.Context, account string) error
+}
@@ -0,0 +1,120 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"tokenlaunch/internal/domain"
+)
+
+const telegramWeb = "https://t.me"
+
+type Telegram struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewTelegram reads public channels through the t.me/s/<channel> web preview.
+// baseURL defaults to https://t.me.
+func NewTelegram(baseURL string) *Telegram {
+	if baseURL == "" {
+		baseURL = telegramWeb
+	}
+
+	return &Telegram{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (t *Telegram) Source() domain.Source {
+	return domain.SourceTelegram
+}
+
+// Scrape fetches the latest posts of a public channel. account is the channel username.
+func (t *Telegram) Scrape(ctx context.Context, account string) ([]domain.Message, error) {
+	url := fmt.Sprintf("%s/s/%s", t.baseURL, account)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", "curl/8.0")
+	req.Header.Set("Accept", "text/html")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []domain.Message
+	doc.Find(".tgme_widget_message[data-post]").Each(func(_ int, post *goquery.Selection) {
+		postID, _ := post.Attr("data-post")
+
+		body := post.Find(".tgme_widget_message_text").First()
+		body.Find("br").ReplaceWithHtml("\n")
+		content := strings.TrimSpace(body.Text())
+		if content == "" {
+			return
+		}
+
+		createdAt := time.Now()
+		if dt, ok := post.Find(".tgme_widget_message_date time").Attr("datetime"); ok {
+			if parsed, err := time.Parse(time.RFC3339, dt); err == nil {
+				createdAt = parsed
+			}
+		}
+
+		author := strings.TrimSpace(post.Find(".tgme_widget_message_owner_name").First().Text())
+		if author == "" {
+			author = account
+		}
+
+		msg := domain.Message{
+			ID:         generateID("telegram:" + postID),
+			ExternalID: postID,
+			Author:     author,
+			Username:   account,
+			Content:    content,
+			Source:     domain.SourceTelegram,
+			CreatedAt:  createdAt,
+		}
+		body.Find("a[href]").Each(func(_ int, a *goquery.Selection) {
+			href, _ := a.Attr("href")
+			if strings.HasPrefix(href, "http") {
+				msg.URLs = appendUnique(msg.URLs, href)
+			}
+		})
+		post.Find(".tgme_widget_message_photo_wrap").Each(func(_ int, m *goquery.Selection) {
+			if style, ok := m.Attr("style"); ok {
+				if i := strings.Index(style, "url('"); i >= 0 {
+					src := style[i+5:]
+					if j := strings.Index(src, "')"); j >= 0 {
+						msg.Media = appendUnique(msg.Media, src[:j])
+					}
+				}
+			}
+		})
+		ExtractEntities(&msg)
+
+		messages = append(messages, msg)
+	})
+
+	return messages, nil
+}
@@ -4,12 +4,17 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 
 	"tokenlaunch/internal/domain"
 )
 
+const messageColumns = `id, external_id, author, username, content, source, created_at,
+	urls, cashtags, mentions, hashtags, media, is_retweet, is_reply, quoted_url,
+	original_author, in_reply_to, thread_id`
+
 type Postgres struct {
 	db *sql.DB
 }
@@ -33,8 +38,8 @@ func (p *Postgres) Close() error {
 
 func (p *Postgres) Save(ctx context.Context, msg domain.Message) error {
 	query := `
-		INSERT INTO messages (id, external_id, author, username, content, source, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO messages (` + messageColumns + `, post_type)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 		ON CONFLICT (id) DO NOTHING
 	`
 
@@ -46,6 +51,18 @@ func (p *Postgres) Save(ctx context.Context, msg domain.Message) error {
 		msg.Content,
 		msg.Source,
 		msg.CreatedAt,
+		pq.Array(msg.URLs),
+		pq.Array(msg.Cashtags),
+		pq.Array(msg.Mentions),
+		pq.Array(msg.Hashtags),
+		pq.Array(msg.Media),
+		msg.IsRetweet,
+		msg.IsReply,
+		msg.QuotedURL,
+		msg.OriginalAuthor,
+		msg.InReplyTo,
+		msg.ThreadID,
+		msg.Type(),
 	)
 
 	return err
@@ -62,22 +79,9 @@ func (p *Postgres) UpdateClassification(ctx context.Context, id, classification,
 }
 
 func (p *Postgres) FindByID(ctx context.Context, id string) (*domain.Message, error) {
-	query := `
-		SELECT id, external_id, author, username, content, source, created_at
-		FROM messages WHERE id = $1
-	`
-
-	var msg domain.Message
-	err := p.db.QueryRowContext(ctx, query, id).Scan(
-		&msg.ID,
-		&msg.ExternalID,
-		&msg.Author,
-		&msg.Username,
-		&msg.Content,
-		&msg.Source,
-		&msg.CreatedAt,
-	)
+	query := `SELECT ` + messageColumns + ` FROM messages WHERE id = $1`
 
+	msg, err := scanMessage(p.db.QueryRowContext(ctx, query, id))
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
@@ -85,12 +89,12 @@ func (p *Postgres) FindByID(ctx context.Context, id string) (*domain.Message, er
 		return nil, err
 	}
 
-	return &msg, nil
+	return msg, nil
 }
 
 func (p *Postgres) FindAll(ctx context.Context, limit, offset int) ([]domain.Message, error) {
 	query := `
-		SELECT id, external_id, author, username, content, source, created_at
+		SELECT ` + messageColumns + `
 		FROM messages ORDER BY created_at DESC LIMIT $1 OFFSET $2
 	`
 
@@ -102,19 +106,11 @@ func (p *Postgres) FindAll(ctx context.Context, limit, offset int) ([]domain.Mes
 
 	var messages []domain.Message
 	for rows.Next() {
-		var msg domain.Message
-		if err := rows.Scan(
-			&msg.ID,
-			&msg.ExternalID,
-			&msg.Author,
-			&msg.Username,
-			&msg.Content,
-			&msg.Source,
-			&msg.CreatedAt,
-		); err != nil {
+		msg, err := scanMessage(rows)
+		if err != nil {
 			return nil, err
 		}
-		messages = append(messages, msg)
+		messages = append(messages, *msg)
 	}
 
 	return messages, rows.Err()
@@ -139,3 +135,309 @@ func (p *Postgres) GetStats(ctx context.Context) (total, launches, endorsements
 	err = p.db.QueryRowContext(ctx, query).Scan(&total, &launches, &endorsements)
 	return
 }
+
+// FindThreadParent returns the latest message by the same author posted
+// within window before msg, which a self-reply continues.
+func (p *Postgres) FindThreadParent(ctx context.Context, msg domain.Message, window time.Duration) (*domain.Message, error) {
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE source = $1 AND username = $2 AND id <> $3
+			AND created_at <= $4 AND created_at >= $5
+		ORDER BY created_at DESC LIMIT 1
+	`
+
+	parent, err := scanMessage(p.db.QueryRowContext(ctx, query,
+		msg.Source, msg.Username, msg.ID, msg.CreatedAt, msg.CreatedAt.Add(-window)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return parent, err
+}
+
+func (p *Postgres) SetThreadID(ctx context.Context, id, threadID string) error {
+	_, err := p.db.ExecContext(ctx, `UPDATE messages SET thread_id = $2 WHERE id = $1`, id, threadID)
+	return err
+}
+
+// SaveThread creates the thread or widens its time span.
+func (p *Postgres) SaveThread(ctx context.Context, t domain.Thread) error {
+	query := `
+		INSERT INTO threads (id, source, username, classification, token, confidence, started_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (id) DO UPDATE SET
+			started_at = LEAST(threads.started_at, EXCLUDED.started_at),
+			updated_at = GREATEST(threads.updated_at, EXCLUDED.updated_at)
+	`
+	_, err := p.db.ExecContext(ctx, query,
+		t.ID,
+		t.Source,
+		t.Username,
+		t.Classification,
+		t.Token,
+		t.Confidence,
+		t.StartedAt,
+		t.UpdatedAt,
+	)
+	return err
+}
+
+func (p *Postgres) FindThread(ctx context.Context, id string) (*domain.Thread, error) {
+	query := `
+		SELECT id, source, username, classification, token, confidence, started_at, updated_at
+		FROM threads WHERE id = $1
+	`
+
+	var t domain.Thread
+	err := p.db.QueryRowContext(ctx, query, id).Scan(
+		&t.ID,
+		&t.Source,
+		&t.Username,
+		&t.Classification,
+		&t.Token,
+		&t.Confidence,
+		&t.StartedAt,
+		&t.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// FindThreadMessages returns the messages of a thread in posting order.
+func (p *Postgres) FindThreadMessages(ctx context.Context, threadID string) ([]domain.Message, error) {
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages WHERE thread_id = $1 ORDER BY created_at ASC
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, threadID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []domain.Message
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, *msg)
+	}
+
+	return messages, rows.Err()
+}
+
+func (p *Postgres) GetProcessingState(ctx context.Context, messageID string) (*domain.ProcessingState, error) {
+	query := `
+		SELECT message_id, stage, classification, token, confidence, reason, alert, updated_at
+		FROM message_processing WHERE message_id = $1
+	`
+
+	var s domain.ProcessingState
+	err := p.db.QueryRowContext(ctx, query, messageID).Scan(
+		&s.MessageID,
+		&s.Stage,
+		&s.Classification,
+		&s.Token,
+		&s.Confidence,
+		&s.Reason,
+		&s.Alert,
+		&s.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+const upsertProcessingState = `
+	INSERT INTO message_processing (message_id, stage, classification, token, confidence, reason, alert, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+	ON CONFLICT (message_id) DO UPDATE SET
+		stage = EXCLUDED.stage,
+		classification = EXCLUDED.classification,
+		token = EXCLUDED.token,
+		confidence = EXCLUDED.confidence,
+		reason = EXCLUDED.reason,
+		alert = EXCLUDED.alert,
+		updated_at = EXCLUDED.updated_at
+`
+
+func (p *Postgres) SetProcessingState(ctx context.Context, s domain.ProcessingState) error {
+	_, err := p.db.ExecContext(ctx, upsertProcessingState,
+		s.MessageID,
+		s.Stage,
+		s.Classification,
+		s.Token,
+		s.Confidence,
+		s.Reason,
+		s.Alert,
+	)
+	return err
+}
+
+func (p *Postgres) RecordClassification(ctx context.Context, s domain.ProcessingState, threadID string, overwrite bool, outbox []domain.OutboxEntry) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// The message and thread only carry positive results, "none" clears them
+	classification := s.Classification
+	if classification == "none" {
+		classification = ""
+	}
+
+	if classification != "" || overwrite {
+		_, err := tx.ExecContext(ctx,
+			`UPDATE messages SET classification = $2, token = $3, confidence = $4 WHERE id = $1`,
+			s.MessageID, classification, s.Token, s.Confidence)
+		if err != nil {
+			return err
+		}
+
+		if threadID != "" {
+			_, err := tx.ExecContext(ctx,
+				`UPDATE threads SET classification = $2, token = $3, confidence = $4 WHERE id = $1`,
+				threadID, classification, s.Token, s.Confidence)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, upsertProcessingState,
+		s.MessageID,
+		s.Stage,
+		s.Classification,
+		s.Token,
+		s.Confidence,
+		s.Reason,
+		s.Alert,
+	)
+	if err != nil {
+		return err
+	}
+
+	// A replayed alert doesn't notify a channel again
+	for _, e := range outbox {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO notification_outbox (message_id, channel, payload)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (message_id, channel) DO NOTHING
+		`, e.MessageID, e.Channel, e.Payload)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (p *Postgres) ClaimOutbox(ctx context.Context, lease time.Duration) (*domain.OutboxEntry, error) {
+	query := `
+		UPDATE notification_outbox
+		SET attempts = attempts + 1, next_attempt_at = NOW() + $1 * INTERVAL '1 millisecond'
+		WHERE id = (
+			SELECT id FROM notification_outbox
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, message_id, channel, payload, status, attempts, last_error, next_attempt_at, created_at, sent_at
+	`
+
+	var e domain.OutboxEntry
+	err := p.db.QueryRowContext(ctx, query, lease.Milliseconds()).Scan(
+		&e.ID,
+		&e.MessageID,
+		&e.Channel,
+		&e.Payload,
+		&e.Status,
+		&e.Attempts,
+		&e.LastError,
+		&e.NextAttemptAt,
+		&e.CreatedAt,
+		&e.SentAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (p *Postgres) MarkOutboxSent(ctx context.Context, id int64) error {
+	query := `
+		UPDATE notification_outbox
+		SET status = 'sent', last_error = '', sent_at = NOW()
+		WHERE id = $1
+	`
+	_, err := p.db.ExecContext(ctx, query, id)
+	return err
+}
+
+func (p *Postgres) RetryOutbox(ctx context.Context, id int64, lastErr string, delay time.Duration) error {
+	query := `
+		UPDATE notification_outbox
+		SET last_error = $2, next_attempt_at = NOW() + $3 * INTERVAL '1 millisecond'
+		WHERE id = $1
+	`
+	_, err := p.db.ExecContext(ctx, query, id, lastErr, delay.Milliseconds())
+	return err
+}
+
+func (p *Postgres) FailOutbox(ctx context.Context, id int64, lastErr string) error {
+	query := `
+		UPDATE notification_outbox
+		SET status = 'failed', last_error = $2
+		WHERE id = $1
+	`
+	_, err := p.db.ExecContext(ctx, query, id, lastErr)
+	return err
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanMessage(row scanner) (*domain.Message, error) {
+	var msg domain.Message
+	err := row.Scan(
+		&msg.ID,
+		&msg.ExternalID,
+		&msg.Author,
+		&msg.Username,
+		&msg.Content,
+		&msg.Source,
+		&msg.CreatedAt,
+		pq.Array(&msg.URLs),
+		pq.Array(&msg.Cashtags),
+		pq.Array(&msg.Mentions),
+		pq.Array(&msg.Hashtags),
+		pq.Array(&msg.Media),
+		&msg.IsRetweet,
+		&msg.IsReply,
+		&msg.QuotedURL,
+		&msg.OriginalAuthor,
+		&msg.InReplyTo,
+		&msg.ThreadID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
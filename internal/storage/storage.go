@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"tokenlaunch/internal/domain"
 )
@@ -13,4 +14,34 @@ type MessageRepository interface {
 	FindAll(ctx context.Context, limit, offset int) ([]domain.Message, error)
 	Exists(ctx context.Context, id string) (bool, error)
 	GetStats(ctx context.Context) (total, launches, endorsements int, err error)
+
+	FindThreadParent(ctx context.Context, msg domain.Message, window time.Duration) (*domain.Message, error)
+	SetThreadID(ctx context.Context, id, threadID string) error
+	SaveThread(ctx context.Context, t domain.Thread) error
+	FindThread(ctx context.Context, id string) (*domain.Thread, error)
+	FindThreadMessages(ctx context.Context, threadID string) ([]domain.Message, error)
+
+	GetProcessingState(ctx context.Context, messageID string) (*domain.ProcessingState, error)
+	SetProcessingState(ctx context.Context, state domain.ProcessingState) error
+
+	// RecordClassification stores a classification result, the message's
+	// processing state and the notifications it triggers in one transaction.
+	// threadID is set when the result applies to a whole thread. Unless
+	// overwrite is set, a "none" result leaves an earlier classification in
+	// place.
+	RecordClassification(ctx context.Context, state domain.ProcessingState, threadID string, overwrite bool, outbox []domain.OutboxEntry) error
+}
+
+type OutboxRepository interface {
+	// ClaimOutbox returns the oldest due pending entry, or nil if there is
+	// none. It counts an attempt and hides the entry from other dispatchers
+	// for lease, after which it is due again unless it was finished.
+	ClaimOutbox(ctx context.Context, lease time.Duration) (*domain.OutboxEntry, error)
+	MarkOutboxSent(ctx context.Context, id int64) error
+	// RetryOutbox records the error of the claimed attempt and schedules the
+	// next one after delay.
+	RetryOutbox(ctx context.Context, id int64, lastErr string, delay time.Duration) error
+	// FailOutbox records the error of the claimed attempt and gives up on the
+	// entry.
+	FailOutbox(ctx context.Context, id int64, lastErr string) error
 }
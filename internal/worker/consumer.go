@@ -3,6 +3,7 @@ package worker
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"log"
@@ -22,19 +23,28 @@ type Consumer struct {
 	consumer    queue.Consumer
 	repo        storage.MessageRepository
 	classifier  classifier.Classifier
-	notifier    notifier.Notifier
+	channels    []string
 	broadcaster Broadcaster
 	feedTmpl    *template.Template
+	reprocess   bool
 }
 
-func NewConsumer(c queue.Consumer, r storage.MessageRepository, cl classifier.Classifier, n notifier.Notifier, b Broadcaster) *Consumer {
+// NewConsumer queues alerts in the outbox for each of channels; a Dispatcher
+// delivers them.
+func NewConsumer(c queue.Consumer, r storage.MessageRepository, cl classifier.Classifier, channels []string, b Broadcaster) *Consumer {
 	tmpl := template.Must(template.New("feed-item").Parse(`
 <div class="item {{.Classification}}">
     <div class="item-head">
-        <div class="item-author">@{{.Username}}</div>
+        <div class="item-author">@{{.Username}}{{if and .Type (ne .Type "original")}} <span class="item-type">{{.Type}}</span>{{end}}</div>
         <div class="item-time">{{.TimeAgo}}</div>
     </div>
     <div class="item-body">{{.Content}}</div>
+    {{if or .Cashtags .URLs}}
+    <div class="item-meta">
+        {{range .Cashtags}}<span class="cashtag">${{.}}</span>{{end}}
+        {{range .URLs}}<a class="item-link" href="{{.}}" target="_blank" rel="noopener">{{.}}</a>{{end}}
+    </div>
+    {{end}}
     {{if .Classification}}
     <div class="tag {{.Classification}}">{{.Classification}}</div>
     {{end}}
@@ -44,50 +54,186 @@ func NewConsumer(c queue.Consumer, r storage.MessageRepository, cl classifier.Cl
 		consumer:    c,
 		repo:        r,
 		classifier:  cl,
-		notifier:    n,
+		channels:    channels,
 		broadcaster: b,
 		feedTmpl:    tmpl,
 	}
 }
 
+// Reprocess makes the consumer run every stage again for messages it already
+// processed and replace their stored classification, even with none, as
+// replays after a classifier change need.
+func (w *Consumer) Reprocess() {
+	w.reprocess = true
+}
+
 func (w *Consumer) Start(ctx context.Context) error {
 	return w.consumer.Consume(ctx, w.handleMessage)
 }
 
+// handleMessage may run concurrently for different accounts, but never for
+// two messages of the same account.
+//
+// Progress is recorded per stage, so a redelivered message skips the stages
+// it already completed instead of being classified and alerted on twice.
 func (w *Consumer) handleMessage(msg domain.Message) error {
 	ctx := context.Background()
 
 	log.Printf("[RECEIVED] @%s: %s", msg.Username, truncate(msg.Content, 60))
 
-	// Save to DB
-	if err := w.repo.Save(ctx, msg); err != nil {
-		log.Printf("[DB ERROR] save failed: %v", err)
+	state, err := w.processingState(ctx, msg.ID)
+	if err != nil {
+		log.Printf("[DB ERROR] load processing state: %v", err)
 		return err
 	}
-	log.Printf("[DB] saved message id=%s", msg.ID)
+	if state.Stage.Reached(domain.StageNotified) {
+		log.Printf("[SKIP] message id=%s already processed", msg.ID)
+		return nil
+	}
+
+	if state.Stage.Reached(domain.StageReceived) {
+		// Resume with the stored copy, which carries the thread link
+		stored, err := w.repo.FindByID(ctx, msg.ID)
+		if err != nil {
+			log.Printf("[DB ERROR] load failed: %v", err)
+			return err
+		}
+		if stored != nil {
+			msg = *stored
+		}
+	} else {
+		// Link self-replies into a thread
+		if err := w.linkThread(ctx, &msg); err != nil {
+			log.Printf("[THREAD ERROR] link failed: %v", err)
+		}
+
+		// Save to DB
+		if err := w.repo.Save(ctx, msg); err != nil {
+			log.Printf("[DB ERROR] save failed: %v", err)
+			return err
+		}
+		log.Printf("[DB] saved message id=%s", msg.ID)
+
+		if err := w.advance(ctx, state, domain.StageReceived); err != nil {
+			return err
+		}
+	}
 
-	// Classify with LLM
+	// Classify with LLM, as a whole thread when the message continues one
+	target, previous := w.threadMessage(ctx, msg)
+
+	var result *classifier.Result
+	if state.Stage.Reached(domain.StageClassified) {
+		log.Printf("[CLASSIFY] reusing result for id=%s", msg.ID)
+		result = &classifier.Result{
+			Classification: classifier.Classification(state.Classification),
+			Token:          state.Token,
+			Confidence:     state.Confidence,
+			Reason:         state.Reason,
+		}
+	} else {
+		// A failed classification leaves the stage at received, so the
+		// message is retried and dead-lettered like any other failure
+		result, err = w.classify(ctx, target, previous, state)
+		if err != nil {
+			return err
+		}
+
+		// The result and its notifications are stored together, so an alert
+		// can't be lost between classifying and notifying
+		outbox, err := w.outbox(msg.ID, target, *result, state.Alert)
+		if err != nil {
+			return err
+		}
+		threadID := ""
+		if target.ID == msg.ThreadID {
+			threadID = msg.ThreadID
+		}
+
+		state.Stage = domain.StageClassified
+		if err := w.repo.RecordClassification(ctx, *state, threadID, w.reprocess, outbox); err != nil {
+			log.Printf("[DB ERROR] record classification: %v", err)
+			return err
+		}
+
+		w.broadcastMessage(msg, result)
+	}
+
+	// Notify if launch/endorsement
+	if state.Alert {
+		log.Printf("[ALERT] %s detected! token=%s, queued for %d channels", result.Classification, result.Token, len(w.channels))
+
+		// Broadcast toast notification
+		toast := fmt.Sprintf(`<div id="toast" class="toast show" hx-swap-oob="true">%s detected: %s</div>`,
+			result.Classification, result.Token)
+		w.broadcaster.Broadcast(toast)
+	}
+
+	if err := w.advance(ctx, state, domain.StageNotified); err != nil {
+		return err
+	}
+
+	log.Printf("[DONE] processed message id=%s", msg.ID)
+	return nil
+}
+
+// classify runs the classifier and keeps the result and alert decision in
+// state.
+func (w *Consumer) classify(ctx context.Context, target domain.Message, previous string, state *domain.ProcessingState) (*classifier.Result, error) {
 	log.Printf("[CLASSIFY] sending to LLM...")
-	result, err := w.classifier.Classify(ctx, msg)
+	result, err := w.classifier.Classify(ctx, target)
 	if err != nil {
 		log.Printf("[CLASSIFY ERROR] %v", err)
-		result = &classifier.Result{Classification: classifier.ClassificationNone}
-	} else {
-		log.Printf("[CLASSIFY] result: type=%s, token=%s, confidence=%.2f, reason=%s",
-			result.Classification, result.Token, result.Confidence, truncate(result.Reason, 50))
+		return nil, err
+	}
+	log.Printf("[CLASSIFY] result: type=%s, token=%s, confidence=%.2f, reason=%s",
+		result.Classification, result.Token, result.Confidence, truncate(result.Reason, 50))
+
+	state.Classification = string(result.Classification)
+	state.Token = result.Token
+	state.Confidence = result.Confidence
+	state.Reason = result.Reason
+	// A thread that was already alerted on is not alerted again for the same result
+	state.Alert = result.Classification != classifier.ClassificationNone &&
+		string(result.Classification) != previous
+
+	return result, nil
+}
+
+// outbox builds the notifications of an alert, one per channel. They are
+// keyed by the message that triggered the alert, which may differ from the
+// thread target they describe.
+func (w *Consumer) outbox(messageID string, target domain.Message, result classifier.Result, alert bool) ([]domain.OutboxEntry, error) {
+	if !alert {
+		return nil, nil
+	}
+
+	payload, err := json.Marshal(notifier.Notification{Message: target, Result: result})
+	if err != nil {
+		return nil, err
 	}
 
-	// Save classification to DB
-	if result.Classification != classifier.ClassificationNone {
-		w.repo.UpdateClassification(ctx, msg.ID, string(result.Classification), result.Token, result.Confidence)
+	entries := make([]domain.OutboxEntry, len(w.channels))
+	for i, channel := range w.channels {
+		entries[i] = domain.OutboxEntry{
+			MessageID: messageID,
+			Channel:   channel,
+			Payload:   payload,
+		}
 	}
+	return entries, nil
+}
 
+func (w *Consumer) broadcastMessage(msg domain.Message, result *classifier.Result) {
 	// Broadcast to SSE
-	view := map[string]string{
+	view := map[string]any{
 		"Username":       msg.Username,
 		"Content":        msg.Content,
 		"TimeAgo":        "just now",
+		"Type":           string(msg.Type()),
 		"Classification": string(result.Classification),
+		"Cashtags":       msg.Cashtags,
+		"URLs":           msg.URLs,
 	}
 
 	if result.Classification == classifier.ClassificationNone {
@@ -99,26 +245,30 @@ func (w *Consumer) handleMessage(msg domain.Message) error {
 		w.broadcaster.Broadcast(buf.String())
 		log.Printf("[SSE] broadcasted to dashboard")
 	}
+}
 
-	// Notify if launch/endorsement
-	if result.Classification != classifier.ClassificationNone {
-		log.Printf("[ALERT] %s detected! token=%s", result.Classification, result.Token)
-
-		// Broadcast toast notification
-		toast := fmt.Sprintf(`<div id="toast" class="toast show" hx-swap-oob="true">%s detected: %s</div>`,
-			result.Classification, result.Token)
-		w.broadcaster.Broadcast(toast)
+// processingState returns the recorded progress of a message, or a fresh
+// state when there is none or the consumer reprocesses everything.
+func (w *Consumer) processingState(ctx context.Context, id string) (*domain.ProcessingState, error) {
+	if w.reprocess {
+		return &domain.ProcessingState{MessageID: id}, nil
+	}
 
-		if err := w.notifier.Notify(ctx, notifier.Notification{
-			Message: msg,
-			Result:  *result,
-		}); err != nil {
-			log.Printf("[NOTIFY ERROR] telegram: %v", err)
-		} else {
-			log.Printf("[NOTIFY] sent to telegram")
-		}
+	state, err := w.repo.GetProcessingState(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		state = &domain.ProcessingState{MessageID: id}
 	}
+	return state, nil
+}
 
-	log.Printf("[DONE] processed message id=%s", msg.ID)
+func (w *Consumer) advance(ctx context.Context, state *domain.ProcessingState, stage domain.ProcessingStage) error {
+	state.Stage = stage
+	if err := w.repo.SetProcessingState(ctx, *state); err != nil {
+		log.Printf("[DB ERROR] record stage %s for id=%s: %v", stage, state.MessageID, err)
+		return err
+	}
 	return nil
 }
@@ -0,0 +1,216 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"tokenlaunch/internal/classifier"
+	"tokenlaunch/internal/domain"
+	"tokenlaunch/internal/queue"
+)
+
+// memoryRepo is a MessageRepository for tests. Messages are never threaded.
+type memoryRepo struct {
+	mu       sync.Mutex
+	messages map[string]domain.Message
+	states   map[string]domain.ProcessingState
+	outbox   []domain.OutboxEntry
+}
+
+func newMemoryRepo() *memoryRepo {
+	return &memoryRepo{
+		messages: make(map[string]domain.Message),
+		states:   make(map[string]domain.ProcessingState),
+	}
+}
+
+func (r *memoryRepo) Save(_ context.Context, msg domain.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.messages[msg.ID]; !ok {
+		r.messages[msg.ID] = msg
+	}
+	return nil
+}
+
+func (r *memoryRepo) UpdateClassification(context.Context, string, string, string, float64) error {
+	return nil
+}
+
+func (r *memoryRepo) FindByID(_ context.Context, id string) (*domain.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	msg, ok := r.messages[id]
+	if !ok {
+		return nil, nil
+	}
+	return &msg, nil
+}
+
+func (r *memoryRepo) FindAll(context.Context, int, int) ([]domain.Message, error) { return nil, nil }
+
+func (r *memoryRepo) Exists(_ context.Context, id string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.messages[id]
+	return ok, nil
+}
+
+func (r *memoryRepo) GetStats(context.Context) (int, int, int, error) { return 0, 0, 0, nil }
+
+func (r *memoryRepo) FindThreadParent(context.Context, domain.Message, time.Duration) (*domain.Message, error) {
+	return nil, nil
+}
+
+func (r *memoryRepo) SetThreadID(context.Context, string, string) error { return nil }
+func (r *memoryRepo) SaveThread(context.Context, domain.Thread) error   { return nil }
+func (r *memoryRepo) FindThread(context.Context, string) (*domain.Thread, error) {
+	return nil, nil
+}
+
+func (r *memoryRepo) FindThreadMessages(context.Context, string) ([]domain.Message, error) {
+	return nil, nil
+}
+
+func (r *memoryRepo) GetProcessingState(_ context.Context, id string) (*domain.ProcessingState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state, ok := r.states[id]
+	if !ok {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+func (r *memoryRepo) SetProcessingState(_ context.Context, state domain.ProcessingState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.states[state.MessageID] = state
+	return nil
+}
+
+func (r *memoryRepo) RecordClassification(_ context.Context, state domain.ProcessingState, _ string, _ bool, outbox []domain.OutboxEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.states[state.MessageID] = state
+	r.outbox = append(r.outbox, outbox...)
+	return nil
+}
+
+func (r *memoryRepo) queued() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.outbox)
+}
+
+func (r *memoryRepo) stage(id string) domain.ProcessingStage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.states[id].Stage
+}
+
+// flakyClassifier fails the first failures calls, then finds a launch.
+type flakyClassifier struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+}
+
+func (c *flakyClassifier) Classify(context.Context, domain.Message) (*classifier.Result, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	if c.calls <= c.failures {
+		return nil, errors.New("rate limited")
+	}
+	return &classifier.Result{Classification: classifier.ClassificationLaunch, Token: "ABC", Confidence: 0.9}, nil
+}
+
+func (c *flakyClassifier) Calls() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls
+}
+
+type nopBroadcaster struct{}
+
+func (nopBroadcaster) Broadcast(string) {}
+
+// waitFor polls cond until it holds or a second passed.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestConsumerRetriesClassifierErrors(t *testing.T) {
+	q := queue.NewMemory(10, queue.RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond})
+	defer q.Close()
+
+	repo := newMemoryRepo()
+	cl := &flakyClassifier{failures: 1}
+	channels := []string{"telegram:1", "telegram:2"}
+	c := NewConsumer(q, repo, cl, channels, nopBroadcaster{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- c.Start(ctx) }()
+
+	msg := domain.Message{
+		ID:        domain.NewID("twitter:1"),
+		Username:  "alice",
+		Content:   "launching $ABC",
+		Source:    domain.SourceTwitter,
+		CreatedAt: time.Now(),
+	}
+	if err := q.Publish(ctx, msg); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	waitFor(t, "message to be notified", func() bool {
+		return repo.stage(msg.ID) == domain.StageNotified
+	})
+
+	if n := cl.Calls(); n != 2 {
+		t.Errorf("classifier called %d times, want a failure and a retry", n)
+	}
+
+	state, _ := repo.GetProcessingState(ctx, msg.ID)
+	if state.Classification != string(classifier.ClassificationLaunch) || !state.Alert {
+		t.Errorf("state = %+v, want an alerted launch", state)
+	}
+	if repo.queued() != len(channels) {
+		t.Errorf("queued %d notifications, want one per channel", repo.queued())
+	}
+
+	// A redelivered message is skipped
+	if err := q.Publish(ctx, msg); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	marker := domain.Message{ID: "marker", Username: "alice", Content: "gm", Source: domain.SourceTwitter}
+	if err := q.Publish(ctx, marker); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	waitFor(t, "marker to be notified", func() bool {
+		return repo.stage(marker.ID) == domain.StageNotified
+	})
+	if n := cl.Calls(); n != 3 {
+		t.Errorf("classifier called %d times, want the redelivery skipped", n)
+	}
+	if repo.queued() != len(channels)*2 {
+		t.Errorf("queued %d notifications, want the redelivery to add none", repo.queued())
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Start: %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package worker
+
+import (
+	"context"
+	"log"
+
+	"tokenlaunch/internal/domain"
+	"tokenlaunch/internal/queue"
+)
+
+// WatchDeliveries reports the outcome of asynchronously published messages
+// to the scraper of their source. Acknowledged messages are counted as
+// published; failed ones are counted and handed back so the next run of the
+// account publishes them again. It returns once the publisher is closed and
+// every outcome was read; ctx is only used to requeue.
+func WatchDeliveries(ctx context.Context, r queue.DeliveryReporter, scrapers []*Scraper) {
+	bySource := make(map[domain.Source]*Scraper, len(scrapers))
+	for _, s := range scrapers {
+		bySource[s.scraper.Source()] = s
+	}
+
+	for d := range r.Deliveries() {
+		s := bySource[d.Message.Source]
+		if d.Err == nil {
+			if s != nil {
+				s.published.Add(1)
+			}
+			continue
+		}
+
+		log.Printf("[DELIVERY ERROR] %s @%s id=%s: %v", d.Message.Source, d.Message.Username, d.Message.ID, d.Err)
+		if s != nil {
+			s.requeue(ctx, d.Account, d.Message)
+		}
+	}
+}
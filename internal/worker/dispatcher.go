@@ -0,0 +1,163 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"tokenlaunch/internal/domain"
+	"tokenlaunch/internal/notifier"
+	"tokenlaunch/internal/storage"
+)
+
+const (
+	// sendTimeout bounds a single delivery attempt.
+	sendTimeout = 30 * time.Second
+	// outboxLease is how long a claimed entry is hidden from other
+	// dispatchers. Entries are claimed one at a time, so it only has to
+	// outlast one send.
+	outboxLease = 4 * sendTimeout
+)
+
+type DispatcherOptions struct {
+	// Interval is how often the outbox is polled for due entries.
+	Interval time.Duration
+	// MaxAttempts attempts, including ones that never finished, mark an
+	// entry failed.
+	MaxAttempts int
+	// Backoff is the delay after the first failure, doubled per attempt up to
+	// MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// Dispatcher delivers notifications from the outbox, one entry per channel,
+// retrying failed deliveries with backoff. Several dispatchers may share an
+// outbox; each entry is claimed by one at a time.
+type Dispatcher struct {
+	repo     storage.OutboxRepository
+	channels map[string]notifier.Notifier
+	opts     DispatcherOptions
+}
+
+func NewDispatcher(repo storage.OutboxRepository, channels map[string]notifier.Notifier, opts DispatcherOptions) *Dispatcher {
+	if opts.Interval <= 0 {
+		opts.Interval = 2 * time.Second
+	}
+	if opts.MaxAttempts < 1 {
+		opts.MaxAttempts = 8
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = 30 * time.Second
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = time.Hour
+	}
+
+	return &Dispatcher{
+		repo:     repo,
+		channels: channels,
+		opts:     opts,
+	}
+}
+
+// Channels returns the names of the configured channels, for the consumer to
+// queue notifications to.
+func (d *Dispatcher) Channels() []string {
+	names := make([]string, 0, len(d.channels))
+	for name := range d.channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (d *Dispatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(d.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.dispatch(ctx)
+		}
+	}
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context) {
+	for ctx.Err() == nil {
+		e, err := d.repo.ClaimOutbox(ctx, outboxLease)
+		if err != nil {
+			log.Printf("[OUTBOX ERROR] claim: %v", err)
+			return
+		}
+		if e == nil {
+			return
+		}
+
+		d.deliver(ctx, *e)
+	}
+}
+
+// deliver sends a claimed entry. Its attempt was already counted by the
+// claim, so an entry whose sends keep crashing the dispatcher is given up on
+// too.
+func (d *Dispatcher) deliver(ctx context.Context, e domain.OutboxEntry) {
+	if e.Attempts > d.opts.MaxAttempts {
+		log.Printf("[NOTIFY FAILED] message id=%s to %s: %d attempts did not finish", e.MessageID, e.Channel, e.Attempts-1)
+		if err := d.repo.FailOutbox(ctx, e.ID, "attempts did not finish"); err != nil {
+			log.Printf("[OUTBOX ERROR] mark failed %d: %v", e.ID, err)
+		}
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+	err := d.send(sendCtx, e)
+	cancel()
+	if err == nil {
+		log.Printf("[NOTIFY] sent message id=%s to %s", e.MessageID, e.Channel)
+		if err := d.repo.MarkOutboxSent(ctx, e.ID); err != nil {
+			log.Printf("[OUTBOX ERROR] mark sent %d: %v", e.ID, err)
+		}
+		return
+	}
+
+	attempts := e.Attempts
+	if attempts >= d.opts.MaxAttempts {
+		log.Printf("[NOTIFY FAILED] message id=%s to %s after %d attempts: %v", e.MessageID, e.Channel, attempts, err)
+		if err := d.repo.FailOutbox(ctx, e.ID, err.Error()); err != nil {
+			log.Printf("[OUTBOX ERROR] mark failed %d: %v", e.ID, err)
+		}
+		return
+	}
+
+	delay := d.opts.Backoff << (attempts - 1)
+	if delay > d.opts.MaxBackoff || delay <= 0 {
+		delay = d.opts.MaxBackoff
+	}
+	log.Printf("[NOTIFY ERROR] message id=%s to %s: %v (attempt %d, retry in %s)", e.MessageID, e.Channel, err, attempts, delay)
+	if err := d.repo.RetryOutbox(ctx, e.ID, err.Error(), delay); err != nil {
+		log.Printf("[OUTBOX ERROR] reschedule %d: %v", e.ID, err)
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, e domain.OutboxEntry) error {
+	// Another process, e.g. a replay, may have queued for a channel this one
+	// doesn't have; retrying gives a dispatcher that has it a chance.
+	n, ok := d.channels[e.Channel]
+	if !ok {
+		return fmt.Errorf("channel %s not configured", e.Channel)
+	}
+
+	var notification notifier.Notification
+	if err := json.Unmarshal(e.Payload, &notification); err != nil {
+		return fmt.Errorf("decode payload: %w", err)
+	}
+
+	return n.Notify(ctx, notification)
+}
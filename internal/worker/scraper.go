@@ -3,90 +3,352 @@ package worker
 import (
 	"context"
 	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"tokenlaunch/internal/domain"
 	"tokenlaunch/internal/queue"
 	"tokenlaunch/internal/redis"
 	"tokenlaunch/internal/scraper"
 )
 
+// scheduleTick is how often due accounts are looked up. Per-account intervals
+// are therefore rounded up to the next tick.
+const scheduleTick = time.Second
+
+type ScraperOptions struct {
+	// Interval is the default for accounts without their own.
+	Interval time.Duration
+	// DedupTTL is how long published message IDs are remembered.
+	DedupTTL time.Duration
+	// Concurrency is the maximum number of accounts fetched at once.
+	Concurrency int
+	// MaxFailures consecutive failures suspend an account.
+	MaxFailures int
+	// MaxBackoff caps the retry delay after failures. Suspended accounts are
+	// probed once per MaxBackoff.
+	MaxBackoff time.Duration
+}
+
 type Scraper struct {
 	scraper   scraper.Scraper
 	publisher queue.Publisher
 	redis     *redis.Client
-	instance  string
-	interval  time.Duration
-	seen      map[string]bool
+	opts      ScraperOptions
+	sem       chan struct{}
+	wg        sync.WaitGroup
+
+	mu       sync.Mutex
+	nextRun  map[string]time.Time
+	running  map[string]bool
+	accounts int
+
+	// Delivery counters since the last health report. With an asynchronous
+	// publisher, WatchDeliveries counts messages once they are acknowledged.
+	published atomic.Int64
+	failed    atomic.Int64
+	async     bool
 }
 
-func NewScraper(s scraper.Scraper, p queue.Publisher, r *redis.Client, instance string, interval time.Duration) *Scraper {
+// NewScraper polls every account of the scraper's source.
+func NewScraper(s scraper.Scraper, p queue.Publisher, r *redis.Client, opts ScraperOptions) *Scraper {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	if opts.MaxFailures < 1 {
+		opts.MaxFailures = 5
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = time.Hour
+	}
+
+	_, async := p.(queue.DeliveryReporter)
+
 	return &Scraper{
 		scraper:   s,
 		publisher: p,
+		async:     async,
 		redis:     r,
-		instance:  instance,
-		interval:  interval,
-		seen:      make(map[string]bool),
+		opts:      opts,
+		sem:       make(chan struct{}, opts.Concurrency),
+		nextRun:   make(map[string]time.Time),
+		running:   make(map[string]bool),
+		accounts:  -1,
 	}
 }
 
 func (w *Scraper) Start(ctx context.Context) {
-	ticker := time.NewTicker(w.interval)
+	ticker := time.NewTicker(scheduleTick)
 	defer ticker.Stop()
 
-	w.scrapeAll(ctx)
+	health := time.NewTicker(w.opts.Interval)
+	defer health.Stop()
+
+	w.dispatch(ctx)
 
 	for {
 		select {
 		case <-ctx.Done():
+			w.wg.Wait()
 			return
 		case <-ticker.C:
-			w.scrapeAll(ctx)
+			w.dispatch(ctx)
+		case <-health.C:
+			w.reportHealth(ctx)
+			w.reportDelivery()
 		}
 	}
 }
 
-func (w *Scraper) scrapeAll(ctx context.Context) {
-	accounts, err := w.redis.GetAccounts(ctx)
+type scheduledAccount struct {
+	username string
+	settings domain.AccountSettings
+	status   domain.AccountStatus
+}
+
+// dispatch starts a fetch for every due account, highest priority first,
+// until the pool is full. Accounts that don't fit wait for the next tick.
+func (w *Scraper) dispatch(ctx context.Context) {
+	source := w.scraper.Source()
+
+	accounts, err := w.redis.GetAccounts(ctx, source)
+	if err != nil {
+		log.Printf("[ERROR] failed to get %s accounts from redis: %v", source, err)
+		return
+	}
+
+	settings, err := w.redis.GetAllAccountSettings(ctx, source)
 	if err != nil {
-		log.Printf("[ERROR] failed to get accounts from redis: %v", err)
+		log.Printf("[ERROR] failed to get %s account settings from redis: %v", source, err)
 		return
 	}
 
-	if len(accounts) == 0 {
-		log.Printf("[SCRAPE] no accounts configured")
+	statuses, err := w.redis.GetAllAccountStatus(ctx, source)
+	if err != nil {
+		log.Printf("[ERROR] failed to get %s account status from redis: %v", source, err)
 		return
 	}
 
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(accounts) != w.accounts {
+		w.accounts = len(accounts)
+		if len(accounts) == 0 {
+			log.Printf("[SCRAPE] no %s accounts configured", source)
+		} else {
+			log.Printf("[SCRAPE] tracking %d %s accounts", len(accounts), source)
+		}
+	}
+
+	// Forget the schedule of removed accounts
+	tracked := make(map[string]bool, len(accounts))
 	for _, account := range accounts {
-		messages, err := w.scraper.Scrape(ctx, account)
+		tracked[account] = true
+	}
+	for account := range w.nextRun {
+		if !tracked[account] {
+			delete(w.nextRun, account)
+		}
+	}
+
+	now := time.Now()
+	var due []scheduledAccount
+	for _, account := range accounts {
+		status := statuses[account]
+		if w.running[account] || now.Before(w.nextRun[account]) || now.Before(status.NextAttempt) {
+			continue
+		}
+		due = append(due, scheduledAccount{username: account, settings: settings[account], status: status})
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].settings.Priority.Rank() > due[j].settings.Priority.Rank()
+	})
+
+	for _, acc := range due {
+		select {
+		case w.sem <- struct{}{}:
+		default:
+			return
+		}
+
+		w.nextRun[acc.username] = now.Add(w.accountInterval(acc.settings))
+		w.running[acc.username] = true
+
+		w.wg.Add(1)
+		go func(acc scheduledAccount) {
+			defer w.wg.Done()
+			defer func() {
+				<-w.sem
+				w.mu.Lock()
+				delete(w.running, acc.username)
+				w.mu.Unlock()
+			}()
+			w.scrapeAccount(ctx, acc)
+		}(acc)
+	}
+}
+
+func (w *Scraper) scrapeAccount(ctx context.Context, acc scheduledAccount) {
+	source := w.scraper.Source()
+	account := acc.username
+	settings := acc.settings
+
+	messages, err := w.scraper.Scrape(ctx, account)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		w.recordFailure(ctx, acc, err)
+		return
+	}
+	w.recordSuccess(ctx, acc)
+
+	log.Printf("[SCRAPE] %s @%s: fetched %d messages", source, account, len(messages))
+
+	// Publish oldest first so the consumer sees threads in posting order
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].CreatedAt.Before(messages[j].CreatedAt)
+	})
+
+	newCount := 0
+	dupCount := 0
+	skipCount := 0
+
+	for _, msg := range messages {
+		if !settings.Allows(msg) {
+			skipCount++
+			continue
+		}
+
+		// The cursor has already moved past this message, so when dedup is
+		// unavailable publish anyway; the consumer skips repeats by ID
+		isNew, err := w.redis.MarkSeen(ctx, msg.ID, w.opts.DedupTTL)
 		if err != nil {
-			log.Printf("[ERROR] @%s: %v", account, err)
+			log.Printf("[ERROR] dedup: %v", err)
+			isNew = true
+		}
+		if !isNew {
+			dupCount++
 			continue
 		}
+		newCount++
 
-		log.Printf("[SCRAPE] @%s: fetched %d tweets", account, len(messages))
+		if err := w.publisher.Publish(queue.WithAccount(ctx, account), msg); err != nil {
+			log.Printf("[ERROR] publish: %v", err)
+			w.requeue(ctx, account, msg)
+			continue
+		}
+		if !w.async {
+			w.published.Add(1)
+		}
+		log.Printf("[QUEUED] @%s: %s", msg.Username, truncate(msg.Content, 60))
+	}
 
-		newCount := 0
-		dupCount := 0
+	log.Printf("[STATS] @%s: new=%d, duplicates=%d, filtered=%d", account, newCount, dupCount, skipCount)
+}
 
-		for _, msg := range messages {
-			if w.seen[msg.ID] {
-				dupCount++
-				continue
-			}
-			w.seen[msg.ID] = true
-			newCount++
+// requeue forgets a message that wasn't delivered so the next run of its
+// account publishes it again.
+func (w *Scraper) requeue(ctx context.Context, account string, msg domain.Message) {
+	w.failed.Add(1)
 
-			if err := w.publisher.Publish(ctx, msg); err != nil {
-				log.Printf("[ERROR] publish: %v", err)
-				continue
-			}
-			log.Printf("[QUEUED] @%s: %s", msg.Username, truncate(msg.Content, 60))
+	if err := w.redis.UnmarkSeen(ctx, msg.ID); err != nil {
+		log.Printf("[ERROR] dedup: %v", err)
+	}
+	if cr, ok := w.scraper.(scraper.CursorResetter); ok && account != "" {
+		if err := cr.ResetCursor(ctx, account); err != nil {
+			log.Printf("[ERROR] reset cursor @%s: %v", account, err)
 		}
+	}
+}
+
+func (w *Scraper) accountInterval(settings domain.AccountSettings) time.Duration {
+	if settings.Interval > 0 {
+		return settings.Interval
+	}
+	return w.opts.Interval
+}
+
+// recordFailure backs the account off exponentially from its interval and
+// suspends it after MaxFailures consecutive failures. Suspended accounts are
+// probed again after MaxBackoff.
+func (w *Scraper) recordFailure(ctx context.Context, acc scheduledAccount, scrapeErr error) {
+	source := w.scraper.Source()
+	now := time.Now()
+
+	status := acc.status
+	status.Failures++
+	status.LastError = scrapeErr.Error()
+	status.LastFailure = now
 
-		log.Printf("[STATS] @%s: new=%d, duplicates=%d, seen_total=%d", account, newCount, dupCount, len(w.seen))
+	backoff := w.accountInterval(acc.settings) << (status.Failures - 1)
+	if backoff > w.opts.MaxBackoff || backoff <= 0 {
+		backoff = w.opts.MaxBackoff
+	}
+
+	if status.Failures >= w.opts.MaxFailures {
+		if status.State != domain.AccountSuspended {
+			log.Printf("[SUSPENDED] %s @%s after %d failures: %v", source, acc.username, status.Failures, scrapeErr)
+		}
+		status.State = domain.AccountSuspended
+		backoff = w.opts.MaxBackoff
+	} else {
+		status.State = domain.AccountBackoff
+		log.Printf("[ERROR] %s @%s: %v (failure %d, retry in %s)", source, acc.username, scrapeErr, status.Failures, backoff)
+	}
+	status.NextAttempt = now.Add(backoff)
+
+	if err := w.redis.SetAccountStatus(ctx, source, acc.username, status); err != nil {
+		log.Printf("[ERROR] failed to store status for @%s: %v", acc.username, err)
+	}
+}
+
+func (w *Scraper) recordSuccess(ctx context.Context, acc scheduledAccount) {
+	if acc.status.Failures == 0 && acc.status.State == "" {
+		return
+	}
+
+	source := w.scraper.Source()
+	if acc.status.State == domain.AccountSuspended {
+		log.Printf("[RECOVERED] %s @%s", source, acc.username)
+	}
+
+	if err := w.redis.ClearAccountStatus(ctx, source, acc.username); err != nil {
+		log.Printf("[ERROR] failed to clear status for @%s: %v", acc.username, err)
+	}
+}
+
+func (w *Scraper) reportHealth(ctx context.Context) {
+	hr, ok := w.scraper.(scraper.HealthReporter)
+	if !ok {
+		return
+	}
+
+	health := hr.Health()
+	healthy := 0
+	for _, h := range health {
+		if h.Healthy {
+			healthy++
+		}
+	}
+	log.Printf("[POOL] %s: %d/%d instances healthy", w.scraper.Source(), healthy, len(health))
+
+	if err := w.redis.SetInstanceHealth(ctx, w.scraper.Source(), health); err != nil {
+		log.Printf("[ERROR] failed to store instance health: %v", err)
+	}
+}
+
+func (w *Scraper) reportDelivery() {
+	published := w.published.Swap(0)
+	failed := w.failed.Swap(0)
+	if published == 0 && failed == 0 {
+		return
 	}
+	log.Printf("[DELIVERY] %s: published=%d, failed=%d", w.scraper.Source(), published, failed)
 }
 
 func truncate(s string, n int) string {
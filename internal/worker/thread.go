@@ -0,0 +1,126 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"tokenlaunch/internal/domain"
+)
+
+// threadWindow is how far back a self-reply looks for the message it continues.
+const threadWindow = 30 * time.Minute
+
+// linkThread attaches a self-reply to the thread of the author's previous
+// message, starting a new thread rooted at that message if needed. A new
+// thread starts with the root's classification, so a launch the root was
+// already alerted on isn't alerted again for the thread.
+func (w *Consumer) linkThread(ctx context.Context, msg *domain.Message) error {
+	if !msg.IsSelfReply() {
+		return nil
+	}
+
+	parent, err := w.repo.FindThreadParent(ctx, *msg, threadWindow)
+	if err != nil || parent == nil {
+		return err
+	}
+
+	thread := domain.Thread{
+		ID:        parent.ThreadID,
+		Source:    msg.Source,
+		Username:  msg.Username,
+		StartedAt: parent.CreatedAt,
+		UpdatedAt: msg.CreatedAt,
+	}
+	if thread.ID == "" {
+		thread.ID = parent.ID
+		if err := w.repo.SetThreadID(ctx, parent.ID, parent.ID); err != nil {
+			return err
+		}
+
+		root, err := w.repo.GetProcessingState(ctx, parent.ID)
+		if err != nil {
+			return err
+		}
+		if root != nil && root.Classification != "none" {
+			thread.Classification = root.Classification
+			thread.Token = root.Token
+			thread.Confidence = root.Confidence
+		}
+	}
+	msg.ThreadID = thread.ID
+
+	return w.repo.SaveThread(ctx, thread)
+}
+
+// threadMessage merges a thread into a single message so it can be
+// classified as a unit. It returns msg unchanged if it is not part of a
+// thread, along with the thread's previous classification.
+func (w *Consumer) threadMessage(ctx context.Context, msg domain.Message) (domain.Message, string) {
+	if msg.ThreadID == "" {
+		return msg, ""
+	}
+
+	thread, err := w.repo.FindThread(ctx, msg.ThreadID)
+	if err != nil {
+		log.Printf("[THREAD ERROR] load thread %s: %v", msg.ThreadID, err)
+		return msg, ""
+	}
+
+	parts, err := w.repo.FindThreadMessages(ctx, msg.ThreadID)
+	if err != nil {
+		log.Printf("[THREAD ERROR] load messages of %s: %v", msg.ThreadID, err)
+		return msg, ""
+	}
+	if len(parts) < 2 {
+		return msg, ""
+	}
+
+	merged := parts[0]
+	merged.ID = msg.ThreadID
+	merged.IsReply = false
+	merged.InReplyTo = ""
+
+	var content strings.Builder
+	for i, part := range parts {
+		if i > 0 {
+			content.WriteString("\n\n")
+		}
+		fmt.Fprintf(&content, "[%d/%d] %s", i+1, len(parts), part.Content)
+
+		if i > 0 {
+			merged.URLs = appendUnique(merged.URLs, part.URLs...)
+			merged.Cashtags = appendUnique(merged.Cashtags, part.Cashtags...)
+			merged.Mentions = appendUnique(merged.Mentions, part.Mentions...)
+			merged.Hashtags = appendUnique(merged.Hashtags, part.Hashtags...)
+			merged.Media = appendUnique(merged.Media, part.Media...)
+		}
+	}
+	merged.Content = content.String()
+
+	log.Printf("[THREAD] @%s: classifying %d-part thread %s", msg.Username, len(parts), msg.ThreadID)
+
+	previous := ""
+	if thread != nil {
+		previous = thread.Classification
+	}
+	return merged, previous
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, existing := range list {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
@@ -0,0 +1,10 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS urls TEXT[] DEFAULT '{}';
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS cashtags TEXT[] DEFAULT '{}';
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS mentions TEXT[] DEFAULT '{}';
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS hashtags TEXT[] DEFAULT '{}';
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS media TEXT[] DEFAULT '{}';
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS is_retweet BOOLEAN DEFAULT FALSE;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS is_reply BOOLEAN DEFAULT FALSE;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS quoted_url TEXT DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_messages_cashtags ON messages USING GIN (cashtags);
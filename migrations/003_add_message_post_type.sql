@@ -0,0 +1,2 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS original_author VARCHAR(255) DEFAULT '';
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS in_reply_to VARCHAR(255) DEFAULT '';
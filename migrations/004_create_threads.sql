@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS threads (
+    id VARCHAR(64) PRIMARY KEY,
+    source VARCHAR(50),
+    username VARCHAR(255),
+    classification VARCHAR(50) DEFAULT '',
+    token VARCHAR(100) DEFAULT '',
+    confidence REAL DEFAULT 0,
+    started_at TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS thread_id VARCHAR(64) DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_messages_thread_id ON messages(thread_id) WHERE thread_id <> '';
+CREATE INDEX IF NOT EXISTS idx_messages_source_username_created_at ON messages(source, username, created_at DESC);
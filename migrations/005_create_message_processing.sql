@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS message_processing (
+    message_id VARCHAR(64) PRIMARY KEY,
+    stage VARCHAR(20) NOT NULL,
+    classification VARCHAR(50) DEFAULT '',
+    token VARCHAR(100) DEFAULT '',
+    confidence REAL DEFAULT 0,
+    reason TEXT DEFAULT '',
+    alert BOOLEAN DEFAULT FALSE,
+    updated_at TIMESTAMP DEFAULT NOW()
+);
@@ -0,0 +1,10 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS post_type VARCHAR(20) DEFAULT 'original';
+
+UPDATE messages SET post_type = CASE
+    WHEN is_retweet THEN 'retweet'
+    WHEN is_reply THEN 'reply'
+    WHEN quoted_url <> '' THEN 'quote'
+    ELSE 'original'
+END;
+
+CREATE INDEX IF NOT EXISTS idx_messages_post_type ON messages(post_type);